@@ -5,12 +5,16 @@ Scans code for database-related patterns across:
 - Python: psycopg2/3, asyncpg, SQLAlchemy, Alembic
 - Go: database/sql, pgx, sqlc, gorm, squirrel
 - Java: JDBC, JPA/Hibernate, Spring JdbcTemplate, Flyway/Liquibase
+- SQL migration files: goose, sql-migrate, golang-migrate
 
-Extracts SQL snippets, file paths, framework labels, and tags.
+Extracts SQL snippets, file paths, framework labels, referenced tables, tags,
+and query-level risks.
 """
 from __future__ import annotations
-from typing import Any
-from dataclasses import dataclass
+from typing import Any, Callable
+from dataclasses import dataclass, field
+import hashlib
+import json
 import re
 from pathlib import Path
 
@@ -26,6 +30,30 @@ class DBCall:
     sql_snippet: str
     call_type: str  # query, execute, migration, transaction, etc.
     tags: list[str]
+    tables: list[str] = field(default_factory=list)
+    risks: list[str] = field(default_factory=list)
+    annotations: list[str] = field(default_factory=list)  # leading query comments
+    query_name: str | None = None  # sqlc-style "name:" annotation
+    dialect: str | None = None  # postgres, mysql, sqlite or a registered dialect
+    upsert: dict[str, Any] | None = None  # conflict target, action, updated columns
+    batch: str | None = None  # pgx.Batch the query is queued on ("func.var")
+    function: str | None = None  # enclosing function (Go and Python only)
+    write_tables: list[str] = field(default_factory=list)  # INSERT/UPDATE/DELETE targets
+    routines: list[dict[str, Any]] = field(default_factory=list)  # stored routine calls: name, args
+    having: str | None = None  # HAVING clause text, if any
+    lock_tables: list[str] = field(default_factory=list)  # FOR UPDATE/SHARE row locks, in join order
+    kind: str = "query"  # query, or finding for a risk-only call site without SQL
+
+
+@dataclass
+class BatchInfo:
+    """Queries queued on one pgx.Batch and sent together."""
+    file_path: str
+    function: str
+    variable: str
+    start_line: int
+    queries: list[DBCall]
+    tables: list[str]
 
 
 # Node patterns
@@ -75,22 +103,61 @@ PYTHON_PATTERNS = {
     r"op\.execute\s*\(\s*['\"`]": ("alembic", "migration"),
 }
 
+# Start of Go query text: a literal, a fmt.Sprintf format string or a
+# string([]byte(...)) conversion
+GO_QUERY_TEXT = r"(?:fmt\.Sprintf\s*\(\s*|string\s*\(\s*\[\]byte\s*\(\s*)?['\"`]"
+
 # Go patterns
 GO_PATTERNS = {
-    # database/sql
-    r"db\.Query\s*\(\s*['\"`]": ("database/sql", "query"),
-    r"db\.Exec\s*\(\s*['\"`]": ("database/sql", "execute"),
-    r"tx\.Exec\s*\(\s*['\"`]": ("database/sql", "transaction"),
+    # database/sql (query text starts as in GO_QUERY_TEXT).
+    # Patterns aren't anchored, so handles wrapped in a struct (s.db.Query) match too.
+    rf"db\.Query\s*\(\s*{GO_QUERY_TEXT}": ("database/sql", "query"),
+    rf"db\.QueryRow\s*\(\s*{GO_QUERY_TEXT}": ("database/sql", "query"),
+    # db.Exec is also GORM's; see _go_exec_framework
+    rf"db\.Exec\s*\(\s*{GO_QUERY_TEXT}": ("database/sql", "execute"),
+    rf"tx\.Exec\s*\(\s*{GO_QUERY_TEXT}": ("database/sql", "transaction"),
+    rf"db\.(?:Query|QueryRow)Context\s*\(\s*\w+\s*,\s*{GO_QUERY_TEXT}": ("database/sql", "query"),
+    rf"db\.ExecContext\s*\(\s*\w+\s*,\s*{GO_QUERY_TEXT}": ("database/sql", "execute"),
+    rf"tx\.(?:Query|QueryRow|Exec)Context\s*\(\s*\w+\s*,\s*{GO_QUERY_TEXT}": ("database/sql", "transaction"),
 
     # pgx
     r"conn\.Query\s*\(\s*ctx": ("pgx", "query"),
     r"pool\.Query\s*\(\s*ctx": ("pgx", "query"),
+    r"(?:conn|pool)\.QueryRow\s*\(\s*ctx": ("pgx", "query"),
+    r"(?:conn|pool)\.Exec\s*\(\s*ctx": ("pgx", "execute"),
+    r"tx\.Query\s*\(\s*ctx": ("pgx", "transaction"),
+    r"tx\.QueryRow\s*\(\s*ctx": ("pgx", "transaction"),
 
     # gorm
-    r"db\.Raw\s*\(\s*['\"`]": ("gorm", "query"),
-    r"db\.Exec\s*\(\s*['\"`]": ("gorm", "execute"),
+    rf"db\.Raw\s*\(\s*{GO_QUERY_TEXT}": ("gorm", "query"),
+
+    # fmt.Fprintf into a strings.Builder/bytes.Buffer; the format string is the query text
+    r"fmt\.Fprintf\s*\(\s*&?[\w.]+\s*,\s*['\"`]\s*(?:SELECT|INSERT|UPDATE|DELETE|WITH)\b": ("fmt.Fprintf", "query-builder"),
+
+    # text/template SQL (template.New(...).Parse("SELECT ... {{.Table}}"))
+    r"\.Parse\s*\(\s*['\"`]\s*(?:SELECT|INSERT|UPDATE|DELETE|WITH)\b": ("text/template", "query"),
 }
 
+# sqlc-generated files: header, source .sql file, and query constants
+SQLC_HEADER_PATTERN = re.compile(r"^// Code generated by sqlc\b", re.MULTILINE)
+SQLC_SOURCE_PATTERN = re.compile(r"^// source: (\S+)", re.MULTILINE)
+SQLC_QUERY_PATTERN = re.compile(r"\b\w+\s*=\s*`(\s*--\s*name:[^`]*)`")
+
+# Query annotations in a sqlc source .sql file ("-- name: GetUser :one")
+SQLC_NAME_PATTERN = re.compile(r"^--\s*name:\s*\w+\s*:\w+", re.MULTILINE)
+
+# Migration tool directives in .sql files: "-- +goose Up", "-- +migrate Down", ...
+MIGRATION_DIRECTIVE_PATTERN = re.compile(
+    r"^--\s*\+(goose|migrate)\s+(Up|Down)\b.*$", re.MULTILINE | re.IGNORECASE
+)
+MIGRATION_STATEMENT_BLOCK_PATTERN = re.compile(
+    r"^--\s*\+(?:goose|migrate)\s+StatementBegin\b.*?$(.*?)"
+    r"^--\s*\+(?:goose|migrate)\s+StatementEnd\b.*?$",
+    re.MULTILINE | re.DOTALL | re.IGNORECASE
+)
+# golang-migrate keeps each direction in its own file: 0001_name.up.sql / .down.sql
+MIGRATION_FILENAME_PATTERN = re.compile(r"\.(up|down)\.sql$", re.IGNORECASE)
+
 # Java patterns
 JAVA_PATTERNS = {
     # JDBC
@@ -111,11 +178,420 @@ JAVA_PATTERNS = {
     r"@FlywayMigration": ("flyway", "migration"),
 }
 
+# Table references: FROM/JOIN/INTO/UPDATE/TABLE followed by an identifier.
+# UPDATE is skipped when it is part of FOR UPDATE, DO UPDATE, KEY UPDATE or ON UPDATE.
+TABLE_REF_PATTERN = re.compile(
+    r"\b(FROM|JOIN|INTO|(?<!FOR\s)(?<!DO\s)(?<!KEY\s)(?<!ON\s)UPDATE|"
+    r"TABLE(?:\s+IF\s+(?:NOT\s+)?EXISTS)?)\s+(?:ONLY\s+)?([\w.\"%{}$]+)",
+    re.IGNORECASE
+)
+
+# Targets of writes; the lookbehinds skip FOR UPDATE and upsert clauses
+WRITE_TARGET_PATTERN = re.compile(
+    r"\b(?:INSERT\s+INTO|(?<!FOR\s)(?<!DO\s)(?<!KEY\s)(?<!ON\s)UPDATE|DELETE\s+FROM)"
+    r"\s+(?:ONLY\s+)?([\w.\"%{}$]+)",
+    re.IGNORECASE
+)
+
+# Postgres DELETE ... USING: the auxiliary tables start after USING
+DELETE_USING_PATTERN = re.compile(
+    r"\bDELETE\s+FROM\s+(?:ONLY\s+)?[\w.\"%{}$]+(?:\s+(?:AS\s+)?(?!USING\b)\w+)?\s+USING\s+",
+    re.IGNORECASE
+)
+
+# Keywords that end a FROM-list entry (so they're never read as an alias)
+FROM_LIST_STOP_WORDS = (
+    r"(?:WHERE|JOIN|INNER|LEFT|RIGHT|FULL|CROSS|NATURAL|ON|USING|GROUP|ORDER|"
+    r"LIMIT|OFFSET|FOR|UNION|EXCEPT|INTERSECT|HAVING|WINDOW|RETURNING|SET|VALUES)"
+)
+
+# Words that can follow a table keyword without being a table name
+NON_TABLE_WORDS = {
+    "select", "set", "nowait", "lateral", "values", "where", "default",
+    "local", "session", "skip", "of",
+}
+
+# CREATE TABLE header; the column list starts after the "("
+CREATE_TABLE_PATTERN = re.compile(
+    r"\s*CREATE\s+(?:TEMP(?:ORARY)?\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.\"]+)\s*\(",
+    re.IGNORECASE
+)
+
+# Table-level constraint openers inside a CREATE TABLE column list
+TABLE_CONSTRAINT_WORDS = {"CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE", "LIKE"}
+
+# Words the manifest's column heuristics must not mistake for columns
+MANIFEST_NON_COLUMNS = {"and", "or", "not", "null", "true", "false", "distinct", "all"}
+
+# Audit-style tables (audit_log, order_events, ...) that should take idempotent inserts
+AUDIT_TABLE_PATTERN = r"(?:^|[._])(?:audit|events?|logs?)(?:_|$)"
+
+# Built-in functions, type names and keywords that can precede "(" without
+# being a call to a user-defined routine
+BUILTIN_SQL_FUNCTIONS = {
+    "abs", "age", "array_agg", "array_length", "avg", "bool_and", "bool_or",
+    "cast", "ceil", "char_length", "coalesce", "concat", "concat_ws", "count",
+    "current_setting", "date_part", "date_trunc", "exp", "extract", "floor",
+    "format", "gen_random_uuid", "generate_series", "greatest", "ifnull",
+    "json_agg", "json_build_object", "jsonb_agg", "jsonb_build_object",
+    "jsonb_set", "least", "left", "length", "ln", "lower", "lpad", "ltrim",
+    "max", "md5", "min", "mod", "nextval", "now", "nullif", "position", "power",
+    "rand", "random", "rank", "regexp_replace", "replace", "right", "round",
+    "row_number", "rpad", "rtrim", "set_config", "split_part", "sqrt",
+    "string_agg", "strftime", "substr", "substring", "sum", "to_char",
+    "to_date", "to_timestamp", "trim", "trunc", "unnest", "upper", "uuid",
+    "char", "varchar", "numeric", "decimal", "float", "int", "integer",
+    "bigint", "smallint", "timestamp", "timestamptz", "time", "interval",
+    "all", "and", "any", "array", "as", "by", "check", "conflict", "exists",
+    "filter", "in", "key", "not", "on", "or", "over", "returning", "row",
+    "select", "some", "unique", "using", "values", "when", "where", "with",
+    "within",
+}
+
+# GROUP BY and HAVING clause bodies, up to the next clause
+GROUP_BY_PATTERN = re.compile(
+    r"\bGROUP\s+BY\s+(.*?)(?=\bHAVING\b|\bORDER\s+BY\b|\bLIMIT\b|\bWINDOW\b|\bUNION\b|$)",
+    re.IGNORECASE | re.DOTALL
+)
+# Row-locking clause, with the optional OF list restricting it to some tables
+ROW_LOCK_PATTERN = re.compile(
+    r"\bFOR\s+(?:NO\s+KEY\s+)?(?:UPDATE|SHARE)\b(?:\s+OF\s+([\w\s.,\"]+?)(?=\s+(?:NOWAIT|SKIP)\b|\s*$|\s*;))?",
+    re.IGNORECASE
+)
+
+# Table and alias after FROM/JOIN, for resolving FOR UPDATE OF names
+TABLE_ALIAS_PATTERN = re.compile(
+    r"\b(?:FROM|JOIN)\s+(?:ONLY\s+)?([\w.\"]+)(?:\s+(?:AS\s+)?(?!" + FROM_LIST_STOP_WORDS + r"\b)(\w+))?",
+    re.IGNORECASE
+)
+
+# Statements that destroy data when run in an up migration (dropped
+# columns are found with DROP_COLUMN_PATTERN)
+MIGRATION_DESTRUCTIVE_PATTERN = re.compile(
+    r"\bDROP\s+(?:TABLE|SCHEMA)\b|\bTRUNCATE\b",
+    re.IGNORECASE
+)
+
+# Go row reads that drop column types: rows.Values() (1) or a Scan's arguments (2)
+UNTYPED_SCAN_PATTERN = re.compile(r"\b(\w+)\.Values\(\)|\.Scan\(([^)]*)\)")
+
+# Column definitions whose value the database fills in when an INSERT omits it
+DATABASE_FILLED_PATTERN = re.compile(
+    r"\bDEFAULT\b|\b(?:SMALL|BIG)?SERIAL\b|\bIDENTITY\b|\bGENERATED\b|\bAUTO_?INCREMENT\b",
+    re.IGNORECASE
+)
+
+# "column <op> literal" comparisons: column (1), string or number literal (2)
+LITERAL_PREDICATE_PATTERN = re.compile(
+    r"(?<![\w.'])(?:\w+\.)?(\w+)\s*(?:=|<>|!=|<=|>=|<|>)\s*('(?:[^']|'')*'|-?\d+(?:\.\d+)?)(?![\w.])"
+)
+
+# Column types compared as numbers and as text, by base type name
+NUMERIC_COLUMN_TYPES = {
+    "smallint", "integer", "int", "int2", "int4", "int8", "bigint", "serial", "bigserial",
+    "smallserial", "numeric", "decimal", "real", "double", "float", "float4", "float8",
+}
+TEXT_COLUMN_TYPES = {"text", "varchar", "char", "character", "citext", "uuid"}
+
+# WHERE clause of a statement, up to the clauses that can follow it
+WHERE_CLAUSE_PATTERN = re.compile(
+    r"\bWHERE\b(.*?)(?=\bGROUP\s+BY\b|\bORDER\s+BY\b|\bLIMIT\b|\bRETURNING\b|\bFOR\s+(?:UPDATE|SHARE)\b|$)",
+    re.IGNORECASE | re.DOTALL
+)
+
+# The database's current time
+CURRENT_TIME_PATTERN = re.compile(
+    r"\bNOW\s*\(\s*\)|\bCURRENT_(?:TIMESTAMP|DATE|TIME)\b|\bLOCALTIMESTAMP\b",
+    re.IGNORECASE
+)
+
+# ALTER TABLE statement: table and the action list
+ALTER_TABLE_PATTERN = re.compile(
+    r"\bALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w.\"]+)\s+(.*)",
+    re.IGNORECASE | re.DOTALL
+)
+
+# Dropped columns in an ALTER TABLE action list (COLUMN is optional in Postgres)
+DROP_COLUMN_PATTERN = re.compile(
+    r"\bDROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?"
+    r"(?!(?:CONSTRAINT|DEFAULT|NOT|IDENTITY|EXPRESSION)\b)\"?(\w+)\"?",
+    re.IGNORECASE
+)
+
+HAVING_PATTERN = re.compile(
+    r"\bHAVING\s+(.*?)(?=\bORDER\s+BY\b|\bLIMIT\b|\bOFFSET\b|\bWINDOW\b|\bUNION\b|$)",
+    re.IGNORECASE | re.DOTALL
+)
+
+# Aggregates whose arguments HAVING may reference freely
+AGGREGATE_FUNCTIONS = {
+    "count", "sum", "avg", "min", "max", "array_agg", "string_agg", "json_agg",
+    "jsonb_agg", "bool_and", "bool_or", "every", "stddev", "variance", "group_concat",
+}
+
+# Words inside a HAVING predicate that aren't column references
+HAVING_KEYWORDS = {
+    "and", "or", "not", "null", "is", "in", "between", "like", "ilike", "true",
+    "false", "distinct", "case", "when", "then", "else", "end", "any", "all",
+    "interval", "as",
+}
+
+# Keywords after which "name(" is a table and column list, not a call
+TABLE_LIST_KEYWORDS = {"into", "table", "references", "update", "only", "exists"}
+
+# Driver imports and connection strings that identify a file's SQL dialect
+DIALECT_MARKERS = {
+    "postgres": [
+        r"github\.com/lib/pq", r"github\.com/jackc/pgx", r"gorm\.io/driver/postgres",
+        r"sql\.Open\(\s*\"(?:postgres|pgx)\"", r"\bpsycopg2?\b", r"\basyncpg\b",
+        r"require\(\s*['\"]pg['\"]\s*\)", r"from\s+['\"]pg['\"]", r"jdbc:postgresql:",
+    ],
+    "mysql": [
+        r"github\.com/go-sql-driver/mysql", r"gorm\.io/driver/mysql",
+        r"sql\.Open\(\s*\"mysql\"", r"\bpymysql\b", r"\bMySQLdb\b", r"mysql\.connector",
+        r"['\"]mysql2(?:/promise)?['\"]", r"jdbc:mysql:",
+    ],
+    "sqlite": [
+        r"github\.com/mattn/go-sqlite3", r"modernc\.org/sqlite", r"gorm\.io/driver/sqlite",
+        r"sql\.Open\(\s*\"sqlite3?\"", r"^\s*import\s+sqlite3\b", r"better-sqlite3", r"jdbc:sqlite:",
+    ],
+}
+
+# SQL features unsupported by a dialect: dialect -> [(feature regex, feature name)]
+DIALECT_UNSUPPORTED_FEATURES = {
+    "postgres": [
+        (r"\bON\s+DUPLICATE\s+KEY\s+UPDATE\b", "ON DUPLICATE KEY UPDATE"),
+    ],
+    "mysql": [
+        (r"\bRETURNING\b", "RETURNING"),
+        (r"\bILIKE\b", "ILIKE"),
+        (r"\bON\s+CONFLICT\b", "ON CONFLICT"),
+    ],
+    "sqlite": [
+        (r"\bILIKE\b", "ILIKE"),
+        (r"\bON\s+DUPLICATE\s+KEY\s+UPDATE\b", "ON DUPLICATE KEY UPDATE"),
+        (r"\bFOR\s+(?:UPDATE|SHARE)\b", "FOR UPDATE/FOR SHARE"),
+    ],
+}
+
+# Placeholder styles a dialect's drivers bind (keys of PLACEHOLDER_STYLES);
+# styles outside the list in a mixed statement go unbound
+DIALECT_PLACEHOLDER_STYLES = {
+    "postgres": ["$n"],
+    "mysql": ["?"],
+    "sqlite": ["?", ":name"],
+}
+
+# Dialect type names mapped to the base types the type checks know
+# (see NUMERIC_COLUMN_TYPES and TEXT_COLUMN_TYPES)
+DIALECT_TYPE_NAMES = {
+    "postgres": {},
+    "mysql": {
+        "tinyint": "smallint", "mediumint": "integer",
+        "tinytext": "text", "mediumtext": "text", "longtext": "text",
+    },
+    "sqlite": {},
+}
+
+# Reserved words that break or confuse parsing when used as unquoted identifiers.
+# Postgres lists only the words its docs mark reserved; non-reserved ones such
+# as time and timestamp are valid column names there.
+RESERVED_WORDS = {
+    "postgres": {
+        "all", "analyse", "analyze", "and", "any", "array", "as", "asc", "both",
+        "case", "cast", "check", "collate", "column", "constraint", "create",
+        "current_date", "current_time", "current_timestamp", "current_user",
+        "default", "desc", "distinct", "do", "else", "end", "except", "false",
+        "fetch", "for", "foreign", "from", "grant", "group", "having", "in",
+        "limit", "not", "null", "offset", "on", "only", "or", "order", "primary",
+        "references", "select", "table", "then", "to", "true", "union", "unique",
+        "user", "using", "when", "where", "window", "with",
+    },
+    "mysql": {
+        "add", "all", "alter", "and", "as", "asc", "between", "by", "case",
+        "check", "column", "condition", "constraint", "create", "cross",
+        "database", "default", "delete", "desc", "distinct", "drop", "else",
+        "exists", "fetch", "for", "foreign", "from", "grant", "group", "having",
+        "in", "index", "inner", "insert", "interval", "into", "is", "join", "key",
+        "keys", "like", "limit", "lock", "match", "not", "null", "on", "option",
+        "or", "order", "primary", "range", "rank", "read", "references", "rows",
+        "select", "set", "table", "then", "to", "union", "unique", "update",
+        "usage", "using", "values", "when", "where", "with", "write",
+    },
+    "sqlite": {
+        "add", "all", "alter", "and", "as", "autoincrement", "between", "by",
+        "case", "check", "collate", "column", "commit", "constraint", "create",
+        "default", "delete", "desc", "distinct", "drop", "else", "escape",
+        "except", "exists", "foreign", "from", "group", "having", "in", "index",
+        "insert", "into", "is", "join", "limit", "not", "null", "on", "or",
+        "order", "primary", "references", "select", "set", "table", "then", "to",
+        "transaction", "union", "unique", "update", "using", "values", "when", "where",
+    },
+}
+
+# Settings that bound how long a statement may wait on a lock
+LOCK_TIMEOUT_PATTERN = re.compile(
+    r"statement_timeout|lock_timeout|context\.WithTimeout|context\.WithDeadline",
+    re.IGNORECASE
+)
+
+# pgx.Batch queued statements: batch.Queue("SELECT ...", args...)
+BATCH_QUEUE_PATTERN = re.compile(r"\b(\w+)\.Queue\s*\(\s*(['\"`])")
+
+# Queries run from a strings.Builder/bytes.Buffer: db.Query(sb.String(), args...)
+BUILDER_QUERY_PATTERN = re.compile(
+    r"\b\w+\.(Query|QueryRow|Exec)(?:Context)?\s*\(\s*(?:\w+\s*,\s*)?(\w+)\.String\(\)"
+)
+
+# database/sql transactions started without a context (db.Begin() vs db.BeginTx(ctx, opts))
+SQL_BEGIN_PATTERN = re.compile(r"\b\w*db\.Begin\s*\(\s*\)", re.IGNORECASE)
+
+# Code that starts a transaction (Go, Python, Java and raw SQL forms)
+TRANSACTION_START_PATTERN = re.compile(
+    r"\.(?:Begin|BeginTx|Transaction|begin|transaction|setAutoCommit)\s*\(|\bBEGIN\b"
+)
+
+# Go transaction handles: tx, err := db.BeginTx(ctx, nil) / tx := db.Begin()
+GO_TX_BEGIN_PATTERN = re.compile(r"\b(\w+)(?:\s*,\s*\w+)?\s*:?=\s*[\w.]+\.(Begin|BeginTx)\s*\(")
+
+# Transactions run at an isolation level that prevents lost updates
+STRONG_ISOLATION_PATTERN = re.compile(
+    r"\bLevel(?:Serializable|RepeatableRead)\b|\bIsoLevel\s*:\s*pgx\.(?:Serializable|RepeatableRead)\b"
+    r"|\bISOLATION\s+LEVEL\s+(?:SERIALIZABLE|REPEATABLE\s+READ)\b",
+    re.IGNORECASE
+)
+
+# GORM conditions given as a struct literal: .Where(&User{Username: name})
+GORM_STRUCT_WHERE_PATTERN = re.compile(
+    r"\.(Where|Or|Not)\s*\(\s*&?((?:\w+\.)?[A-Z]\w*)\s*\{\s*\w+\s*:"
+)
+
+# Package init function (methods named init have a receiver and don't match)
+GO_INIT_PATTERN = re.compile(r"^func\s+init\s*\(\s*\)", re.MULTILINE)
+
+# A context.Context available in Go code
+GO_CONTEXT_PATTERN = re.compile(r"\bcontext\.Context\b|\bctx\s*:?=")
+
+# cgo: import "C" and the comment preamble directly above it
+CGO_IMPORT_PATTERN = re.compile(r"^\s*import\s+\"C\"", re.MULTILINE)
+CGO_PREAMBLE_PATTERN = re.compile(
+    r"(?:/\*(?:(?!\*/).)*\*/|(?:^[ \t]*//[^\n]*\n)+)(?=\s*import\s+\"C\")",
+    re.DOTALL | re.MULTILINE
+)
+
+# Go string slice literals ([]string{...}) and SQL-looking migration elements
+STRING_SLICE_PATTERN = re.compile(r"\[\]string\s*\{")
+GO_STRING_LITERAL_PATTERN = re.compile(r"`([^`]*)`|\"((?:[^\"\\\n]|\\.)*)\"")
+MIGRATION_ELEMENT_PATTERN = re.compile(r"\s*(?:CREATE|ALTER|DROP|INSERT)\b", re.IGNORECASE)
+
+# Enclosing function (or Go method) name
+FUNC_NAME_PATTERNS = {
+    "go": re.compile(r"func\s+(?:\([^)]*\)\s*)?(\w+)"),
+    "python": re.compile(r"(?:async\s+)?def\s+(\w+)"),
+}
+
+# Function declarations at the start of a line, capturing the indentation
+FUNCTION_START_PATTERNS = {
+    "go": re.compile(r"^()func\s", re.MULTILINE),
+    "python": re.compile(r"^([ \t]*)(?:async\s+)?def\s", re.MULTILINE),
+}
+
+# Python lines that end the function body before them when indented no deeper
+PYTHON_BLOCK_START_PATTERN = re.compile(r"^([ \t]*)(?:(?:async\s+)?def\s|class\s|@)", re.MULTILINE)
+
+# Bind placeholder styles: Postgres $n, MySQL/SQLite ?, named :param (not ::casts).
+# A ? followed by | or &, a $n or a literal is a Postgres JSONB operator.
+PLACEHOLDER_STYLES = {
+    "$n": re.compile(r"\$\d+"),
+    "?": re.compile(r"\?(?![|&]|\s*(?:\$\d|'))"),
+    ":name": re.compile(r"(?<![:\w]):[A-Za-z_]\w*"),
+}
+
+# Statements that are DDL
+DDL_START_PATTERN = re.compile(r"\s*(?:CREATE|ALTER|DROP)\b", re.IGNORECASE)
+
+# Function parameter lists, and parameters that carry string input
+SIGNATURE_PATTERNS = {
+    "go": re.compile(r"func\s+(?:\([^)]*\)\s*)?\w+\s*\(([^)]*)\)"),
+    "python": re.compile(r"(?:async\s+)?def\s+\w+\s*\(([^)]*)\)"),
+}
+STRING_PARAM_PATTERNS = {
+    "go": re.compile(r"\b(?:string|interface\s*\{\s*\}|any)\b"),
+    "python": re.compile(r":\s*str\b"),
+}
+
+# Names introduced by WITH (CTEs), which are not real tables
+CTE_NAME_PATTERN = re.compile(r"(?:\bWITH(?:\s+RECURSIVE)?|,)\s*\"?(\w+)\"?\s+AS\s*\(", re.IGNORECASE)
+
+# Go text/template and similar {{ ... }} actions inside query text
+TEMPLATE_ACTION_PATTERN = re.compile(r"\{\{.*?\}\}", re.DOTALL)
+
+# fmt verbs in a format string (%% is a literal percent sign)
+FORMAT_VERB_PATTERN = re.compile(r"(?<!%)%[-+# 0]*\d*(?:\.\d+)?[sdvqxf]")
+
+# Interpolation markers that can stand in for an identifier: fmt verbs
+# in Go, ${...} / {...} template and f-string fields elsewhere (Python's
+# %s is a DB-API bind parameter)
+IDENTIFIER_MARKERS = {
+    "go": r"%[-+# 0]*\d*[svq]",
+    "default": r"\$?\{[^}]*\}",
+}
+
+# Markers of a table expression built at runtime (Sprintf verbs, templates, f-strings)
+DYNAMIC_TABLE_PATTERN = re.compile(r"%[sdvq]|\{")
+
+
+def register_dialect(
+    name: str,
+    markers: list[str],
+    reserved_words: set[str] | None = None,
+    unsupported_features: list[tuple[str, str]] | None = None,
+    placeholder_styles: list[str] | None = None,
+    type_names: dict[str, str] | None = None
+) -> None:
+    """Register a SQL dialect used by the dialect-specific checks.
+
+    The built-in postgres, mysql and sqlite dialects are entries in the same
+    tables, so registering an existing name replaces it.
+
+    Args:
+        name: Dialect name (e.g. "cockroachdb")
+        markers: Regexes for driver imports / connection strings that identify it
+        reserved_words: Lowercase words that can't be unquoted identifiers
+        unsupported_features: (feature regex, feature name) pairs the dialect rejects
+        placeholder_styles: Placeholder styles its drivers bind ("$n", "?",
+            ":name"); without them any mix of styles is flagged
+        type_names: Dialect type name -> base type for the type checks
+            (e.g. {"string": "text", "int64": "bigint"})
+    """
+    unknown = sorted(set(placeholder_styles or []) - set(PLACEHOLDER_STYLES))
+    if unknown:
+        raise ValueError(f"Unknown placeholder styles: {', '.join(unknown)}")
+
+    DIALECT_MARKERS[name] = list(markers)
+    RESERVED_WORDS[name] = set(reserved_words or ())
+    DIALECT_UNSUPPORTED_FEATURES[name] = list(unsupported_features or [])
+    if placeholder_styles:
+        DIALECT_PLACEHOLDER_STYLES[name] = list(placeholder_styles)
+    else:
+        DIALECT_PLACEHOLDER_STYLES.pop(name, None)
+    DIALECT_TYPE_NAMES[name] = {
+        dialect_type.lower(): base.lower() for dialect_type, base in (type_names or {}).items()
+    }
+
 
 def discover_db_calls(
     file_path: str,
     content: str,
-    language: str
+    language: str,
+    table_patterns: dict[str, str] | None = None,
+    default_schemas: list[str] | None = None,
+    allowed_schemas: list[str] | None = None,
+    allowed_tables: list[str] | None = None,
+    audit_table_pattern: str | None = None,
+    dialect: str | None = None,
+    query_wrappers: dict[str, int] | None = None,
+    optional_checks: list[str] | None = None
 ) -> list[DBCall]:
     """Discover database calls in a file.
 
@@ -123,6 +599,29 @@ def discover_db_calls(
         file_path: Path to file
         content: File content
         language: Programming language
+        table_patterns: Optional regex -> canonical table mapping used to
+            normalize sharded/prefixed table names (e.g. r"tenant_[^.]+\.users" -> "users").
+            Patterns are matched against the table expression as written in the
+            source, so dynamic names include their format verb (tenant_%d.users).
+        default_schemas: Optional schemas tables are expected to be qualified
+            with (e.g. ["public"]). When given, table references without a
+            schema prefix are flagged to avoid search_path ambiguity.
+        allowed_schemas: Optional schemas the code may touch. Together with
+            allowed_tables, qualified references outside the allowlist are
+            flagged as forbidden.
+        allowed_tables: Optional tables (bare or schema-qualified) the code
+            may touch in addition to allowed_schemas.
+        audit_table_pattern: Regex for audit/event/log tables whose plain
+            INSERTs are flagged as non-idempotent (defaults to
+            AUDIT_TABLE_PATTERN).
+        dialect: SQL dialect for the dialect-specific checks; detected from
+            the file's drivers when omitted (see register_dialect).
+        query_wrappers: Optional function name -> SQL argument index for
+            project helpers that execute queries (e.g. {"tracedQuery": 2}
+            for tracedQuery(ctx, db, "SELECT ...", args)). Calls to them
+            are analyzed like native Query calls.
+        optional_checks: Names of off-by-default checks to run (see
+            OPTIONAL_CHECKS).
 
     Returns:
         List of discovered DB calls
@@ -138,211 +637,2885 @@ def discover_db_calls(
         patterns = GO_PATTERNS
     elif language == "java":
         patterns = JAVA_PATTERNS
+    elif language == "sql":
+        calls = (
+            _discover_migration_statements(file_path, content, table_patterns)
+            or _discover_sqlc_source_queries(file_path, content, table_patterns)
+        )
+        for call in calls:
+            call.risks.extend(_placeholder_risks(_strip_sql_comments(call.sql_snippet), dialect))
+        _flag_unqualified_tables(calls, default_schemas)
+        _flag_forbidden_tables(calls, allowed_schemas, allowed_tables)
+        _flag_audit_inserts(calls, audit_table_pattern or AUDIT_TABLE_PATTERN)
+        _run_optional_checks(calls, content, language, optional_checks)
+        return calls
     else:
         return calls
 
+    # cgo preambles are C source; blank them out (keeping line numbers)
+    # so only the Go part of the file is analyzed
+    is_cgo = language == "go" and CGO_IMPORT_PATTERN.search(content) is not None
+    if is_cgo:
+        content = CGO_PREAMBLE_PATTERN.sub(lambda m: re.sub(r"[^\n]", " ", m.group(0)), content)
+
     # Search for patterns
     for pattern, (framework, call_type) in patterns.items():
         for match in re.finditer(pattern, content, re.IGNORECASE):
             # Extract SQL snippet
             sql_snippet = _extract_sql_snippet(content, match.start(), language)
 
-            # Get line number
-            line_num = content[:match.start()].count("\n") + 1
-
-            # Determine tags
-            tags = _determine_tags(sql_snippet, call_type, framework)
-
-            calls.append(DBCall(
-                file_path=file_path,
-                start_line=line_num,
-                end_line=line_num + sql_snippet.count("\n"),
-                language=language,
-                framework=framework,
-                sql_snippet=sql_snippet[:500],  # Limit length
-                call_type=call_type,
-                tags=tags
+            call_framework = framework
+            if language == "go" and re.match(r"db\.Exec\s*\(", match.group(0)):
+                call_framework = _go_exec_framework(content, match.start())
+
+            calls.append(_build_call(
+                file_path, content, language, call_framework, call_type,
+                sql_snippet, match.start(), table_patterns
             ))
 
+    if query_wrappers:
+        calls.extend(_discover_query_wrappers(
+            file_path, content, language, query_wrappers, table_patterns
+        ))
+
+    # sqlc-generated code keeps its queries in constants, not at call sites
+    if language == "go" and SQLC_HEADER_PATTERN.search(content):
+        calls.extend(_discover_sqlc_queries(file_path, content, table_patterns))
+
+    if language == "go":
+        calls.extend(_discover_migration_slices(file_path, content, table_patterns))
+        calls.extend(_discover_pgx_batches(file_path, content, table_patterns))
+        calls.extend(_discover_builder_queries(file_path, content, table_patterns))
+        # GORM's db.Begin() returns a *gorm.DB and has no BeginTx counterpart
+        if "gorm.io/gorm" in content:
+            calls.extend(_discover_gorm_struct_where(file_path, content))
+        else:
+            calls.extend(_discover_sql_begin(file_path, content))
+        calls.extend(_discover_empty_transactions(file_path, content))
+
+    if is_cgo:
+        for call in calls:
+            call.tags.append("cgo")
+
+    _flag_non_transactional_writes(calls, content, language)
+    if language == "go":
+        _flag_queries_in_init(calls, content)
+
+    # Dialect-specific checks need the drivers the whole file uses
+    dialect = dialect or _detect_dialect(content)
+    for call in calls:
+        call.dialect = dialect
+        sql_text = _strip_sql_comments(call.sql_snippet)
+        call.risks.extend(_detect_dialect_risks(sql_text, dialect))
+        call.risks.extend(_placeholder_risks(sql_text, dialect))
+        for word in _find_reserved_identifiers(sql_text, dialect):
+            call.risks.append(
+                f"Reserved word '{word}' used as an unquoted identifier - "
+                f"quote or rename it for {dialect} portability"
+            )
+
+    _flag_unqualified_tables(calls, default_schemas)
+    _flag_forbidden_tables(calls, allowed_schemas, allowed_tables)
+    _flag_audit_inserts(calls, audit_table_pattern or AUDIT_TABLE_PATTERN)
+    _run_optional_checks(calls, content, language, optional_checks)
+
+    # Generated copies of sqlc queries are reported on their source .sql file
+    for call in calls:
+        if "generated" in call.tags:
+            call.risks = []
+
     return calls
 
 
-def _extract_sql_snippet(content: str, start_pos: int, language: str) -> str:
-    """Extract SQL snippet from match position.
+def _run_optional_checks(
+    calls: list[DBCall],
+    content: str,
+    language: str,
+    optional_checks: list[str] | None
+) -> None:
+    """Run the requested off-by-default checks over a file's calls."""
+    _validate_optional_checks(optional_checks)
+    for name in optional_checks or []:
+        OPTIONAL_CHECKS[name](calls, content, language)
 
-    Args:
-        content: File content
-        start_pos: Match start position
-        language: Programming language
 
-    Returns:
-        Extracted SQL snippet
+def _validate_optional_checks(optional_checks: list[str] | None) -> None:
+    """Reject check names that aren't in OPTIONAL_CHECKS."""
+    unknown = sorted(set(optional_checks or []) - set(OPTIONAL_CHECKS))
+    if unknown:
+        raise ValueError(f"Unknown optional checks: {', '.join(unknown)}")
+
+
+def _flag_nondeterministic_predicates(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to app queries comparing against the current time in WHERE."""
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            where = WHERE_CLAUSE_PATTERN.search(statement)
+            clock = CURRENT_TIME_PATTERN.search(where.group(1)) if where else None
+            if clock:
+                call.risks.append(
+                    f"{clock.group(0).upper()} in a WHERE predicate makes results depend on when the "
+                    "query runs - pass the time as a parameter to keep it deterministic and testable"
+                )
+                break
+
+
+def _flag_nullable_foreign_keys(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to CREATE TABLE statements with foreign key columns that allow NULL."""
+    for call in calls:
+        if "migration-down" in call.tags:
+            continue
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            ddl = CREATE_TABLE_PATTERN.match(statement)
+            if not ddl:
+                continue
+            table = ddl.group(1).strip('"')
+            body = statement[ddl.end():]
+            definitions = {}  # column -> its definition
+            required = set()  # columns covered by a table-level PRIMARY KEY
+            references = []  # foreign key columns in declaration order
+            for column_def in _split_top_level(body[:_closing_paren(body)], ","):
+                column = re.match(r"[\"`]?(\w+)[\"`]?\s+\w+", column_def)
+                if column and column.group(1).upper() not in TABLE_CONSTRAINT_WORDS:
+                    definitions[column.group(1).lower()] = column_def
+                    if re.search(r"\bREFERENCES\b", column_def, re.IGNORECASE):
+                        references.append(column.group(1).lower())
+                    continue
+                for kind, names in re.findall(r"\b(PRIMARY|FOREIGN)\s+KEY\s*\(([^)]*)\)", column_def, re.IGNORECASE):
+                    columns = [name.strip().strip('"`').lower() for name in names.split(",")]
+                    if kind.upper() == "PRIMARY":
+                        required.update(columns)
+                    else:
+                        references.extend(columns)
+
+            for column in dict.fromkeys(references):
+                definition = definitions.get(column)
+                if definition is None or column in required:
+                    continue
+                if not re.search(r"\bNOT\s+NULL\b|\bPRIMARY\s+KEY\b", definition, re.IGNORECASE):
+                    call.risks.append(
+                        f"Foreign key column {table}.{column} is nullable - add NOT NULL unless "
+                        "the relationship is optional"
+                    )
+
+
+def _flag_untyped_scans(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to Go queries whose rows are read as untyped values.
+
+    rows.Values() and Scan destinations declared interface{} or any lose
+    the column types. The risk goes on the nearest query above the read
+    in the same function.
     """
-    # Find the opening quote after the match
-    quote_chars = ['"', "'", '`']
-    quote_start = None
-    quote_char = None
+    if language != "go":
+        return
+
+    line_starts = [0] + [m.end() for m in re.finditer(r"\n", content)]
+    for match in UNTYPED_SCAN_PATTERN.finditer(content):
+        scope = _enclosing_function(content, match.start(), "go")
+        if match.group(1):
+            read = f"{match.group(1)}.Values()"
+        else:
+            names = [arg.strip().lstrip("&").removesuffix("...") for arg in match.group(2).split(",")]
+            untyped = [
+                name for name in names
+                if re.fullmatch(r"\w+", name) and re.search(
+                    rf"\bvar\s+(?:\w+\s*,\s*)*{name}\b(?:\s*,\s*\w+)*\s+(?:\[\])?(?:interface\{{\}}|any)\b"
+                    rf"|\b{name}\s*:=\s*(?:make\(\s*)?\[\](?:interface\{{\}}|any)\b",
+                    scope
+                )
+            ]
+            if not untyped:
+                continue
+            read = f"Scan into {', '.join(untyped)}"
+
+        line = content[:match.start()].count("\n") + 1
+        queries = [
+            call for call in calls
+            if call.sql_snippet and call.call_type != "migration" and call.start_line <= line
+            and _enclosing_function(content, line_starts[call.start_line - 1], "go") == scope
+        ]
+        if queries:
+            max(queries, key=lambda call: call.start_line).risks.append(
+                f"{read} at line {line} reads rows as untyped interface{{}} values - scan into "
+                "typed variables or a struct (e.g. pgx.RowToStructByName)"
+            )
 
-    for i in range(start_pos, min(start_pos + 100, len(content))):
-        if content[i] in quote_chars:
-            quote_start = i
-            quote_char = content[i]
+
+def _flag_dynamic_sql(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to every query whose text isn't a constant literal.
+
+    Strict mode for services that only allow bind parameters: Sprintf and
+    other formatting, template actions, concatenation and non-constant
+    builder writes all count, whatever the interpolated value is.
+    """
+    line_starts = [0] + [m.end() for m in re.finditer(r"\n", content)]
+    for call in calls:
+        if not call.sql_snippet or call.call_type == "migration" or call.kind == "finding":
+            continue
+
+        # The source from the call through the end of its literal
+        start = line_starts[call.start_line - 1]
+        literal = content.find(call.sql_snippet[:40], start)
+        end = content.find("\n", literal + len(call.sql_snippet)) if literal != -1 else -1
+        source = content[start:end if end != -1 else len(content)]
+        if literal == -1:
+            source = source.split("\n", 1)[0]
+
+        if call.framework in ("fmt.Fprintf", "strings.Builder"):
+            dynamic = FORMAT_VERB_PATTERN.search(call.sql_snippet) and (
+                call.framework == "fmt.Fprintf" or "dynamic-query" in call.tags
+            )
+            how = "written to a builder from non-constant values"
+        elif re.search(r"\b(?:fmt\.Sprintf|String\.format)\s*\(|\.format\s*\(|\bf['\"]|['\"]\s*%\s*[(\w]", source):
+            dynamic, how = True, "formatted at runtime"
+        elif TEMPLATE_ACTION_PATTERN.search(call.sql_snippet) or (
+            language != "go" and "${" in call.sql_snippet
+        ):
+            dynamic, how = True, "interpolated from a template"
+        else:
+            dynamic = re.search(r"['\"`]\s*\+|\+\s*['\"`]", source)
+            how = "concatenated"
+
+        if dynamic:
+            call.risks.append(
+                f"Strict parameterization: query text is {how} - only constant SQL "
+                "with bind parameters is allowed"
+            )
+
+
+def _flag_weak_isolation(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to read-modify-write transactions at the default isolation level.
+
+    A transaction that reads rows without FOR UPDATE/SHARE and later
+    updates the same table can lose a concurrent update unless it runs
+    at REPEATABLE READ or SERIALIZABLE. The risk goes on the UPDATE.
+    """
+    line_starts = [0] + [m.end() for m in re.finditer(r"\n", content)]
+    by_function: dict[tuple[str, str], list[DBCall]] = {}
+    for call in calls:
+        if call.function and call.sql_snippet and call.call_type != "migration":
+            scope = _enclosing_function(content, line_starts[call.start_line - 1], language)
+            by_function.setdefault((call.function, scope), []).append(call)
+
+    for (function, scope), scoped in by_function.items():
+        if not TRANSACTION_START_PATTERN.search(scope) or STRONG_ISOLATION_PATTERN.search(scope):
+            continue
+
+        scoped.sort(key=lambda call: call.start_line)
+        for index, write in enumerate(scoped):
+            if "update" not in write.tags:
+                continue
+            read = next((
+                call for call in scoped[:index]
+                if "select" in call.tags and not call.write_tables
+                and not ROW_LOCK_PATTERN.search(call.sql_snippet)
+                and any(_same_table(table, target) for table in call.tables for target in write.write_tables)
+            ), None)
+            if read:
+                write.risks.append(
+                    f"Read-modify-write in {function}: rows read at line {read.start_line} without a lock "
+                    "are updated at the default isolation level - concurrent transactions can lose "
+                    "updates; read with SELECT ... FOR UPDATE or use SERIALIZABLE"
+                )
+
+
+def _flag_suspicious_distinct(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to SELECT DISTINCT queries reading more than one table.
+
+    DISTINCT over a join often hides duplicates from a missing or wrong
+    join condition. A join with no condition at all gets the stronger
+    wording.
+    """
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            if not re.search(r"\bSELECT\s+DISTINCT\b", statement, re.IGNORECASE):
+                continue
+            tables, _ = _extract_tables(statement)
+            if len(tables) < 2 and not re.search(r"\bJOIN\b", statement, re.IGNORECASE):
+                continue
+
+            unconstrained = _find_cartesian_joins(statement)
+            if unconstrained:
+                call.risks.append(
+                    f"SELECT DISTINCT over an unconstrained join ({'; '.join(unconstrained)}) - "
+                    "DISTINCT is hiding the duplicated rows; add the missing join condition"
+                )
+            else:
+                call.risks.append(
+                    f"SELECT DISTINCT over a join of {', '.join(tables)} - check the join keys; "
+                    "DISTINCT can hide duplicates from a missing or wrong condition"
+                )
             break
 
-    if not quote_start:
-        return ""
 
-    # Find closing quote (simple - doesn't handle escapes perfectly)
-    quote_end = content.find(quote_char, quote_start + 1)
-    if quote_end == -1:
-        return ""
+def _flag_non_transactional_writes(calls: list[DBCall], content: str, language: str) -> None:
+    """Add a risk to functions making several writes outside a transaction.
 
-    snippet = content[quote_start + 1:quote_end]
+    Functions that start a transaction (Begin/BeginTx, db.Transaction,
+    BEGIN) are skipped, as are functions carrying an
+    "independent-writes" comment, which marks writes that don't need to
+    succeed together.
+    """
+    # Grouped by the function's source, so same-named methods of
+    # different classes stay apart
+    line_starts = [0] + [m.end() for m in re.finditer(r"\n", content)]
+    by_function: dict[tuple[str, str], list[DBCall]] = {}
+    for call in calls:
+        is_write = call.write_tables and call.call_type not in ("transaction", "migration", "batch")
+        if call.function and is_write and "transactions" not in call.tags:
+            scope = _enclosing_function(content, line_starts[call.start_line - 1], language)
+            by_function.setdefault((call.function, scope), []).append(call)
 
-    # For template literals in JS/TS, handle multiline
-    if language in ("javascript", "typescript") and quote_char == '`':
-        # Extract up to 500 chars or closing backtick
-        max_end = min(quote_start + 500, len(content))
-        for i in range(quote_start + 1, max_end):
-            if content[i] == '`' and (i == 0 or content[i-1] != '\\'):
-                snippet = content[quote_start + 1:i]
-                break
+    for (function, scope), writes in by_function.items():
+        if len(writes) < 2:
+            continue
+        if TRANSACTION_START_PATTERN.search(scope) or "independent-writes" in scope:
+            continue
 
-    return snippet.strip()
+        lines = ", ".join(str(call.start_line) for call in writes)
+        for call in writes:
+            call.risks.append(
+                f"{function} makes {len(writes)} writes (lines {lines}) outside a transaction - "
+                "a failure part way leaves them inconsistent"
+            )
 
 
-def _determine_tags(sql_snippet: str, call_type: str, framework: str) -> list[str]:
-    """Determine tags for a DB call.
+def _flag_queries_in_init(calls: list[DBCall], content: str) -> None:
+    """Add a risk to queries issued directly from a Go package init()."""
+    for match in GO_INIT_PATTERN.finditer(content):
+        end = _function_end(content, FUNCTION_START_PATTERNS["go"].match(content, match.start()), "go")
+        first_line = content[:match.start()].count("\n") + 1
+        last_line = content[:end].count("\n") + 1
 
-    Args:
-        sql_snippet: SQL code snippet
-        call_type: Type of call
-        framework: Framework name
+        for call in calls:
+            if first_line <= call.start_line <= last_line:
+                call.risks.append(
+                    "Database call in init() runs at import time - errors can't be returned "
+                    "and tests can't avoid it; move it to an explicit setup function"
+                )
 
-    Returns:
-        List of tags
+
+def _flag_unqualified_tables(calls: list[DBCall], default_schemas: list[str] | None) -> None:
+    """Add a risk for each table referenced without a schema prefix.
+
+    CTE names are local to the statement and are never flagged.
     """
-    tags = ["database"]
+    if default_schemas is None:
+        return
 
-    # Framework tag
-    if framework:
-        tags.append(f"db-{framework}")
+    for call in calls:
+        cte_names = {name.lower() for name in CTE_NAME_PATTERN.findall(call.sql_snippet)}
+        for table in call.tables:
+            if "." in table or table.strip('"').lower() in cte_names:
+                continue
+            suggestion = ", ".join(f"{schema}.{table}" for schema in default_schemas) or f"<schema>.{table}"
+            call.risks.append(
+                f"Unqualified table '{table}' resolves via search_path - "
+                f"qualify it as {suggestion}"
+            )
+
+
+def _flag_forbidden_tables(
+    calls: list[DBCall],
+    allowed_schemas: list[str] | None,
+    allowed_tables: list[str] | None
+) -> None:
+    """Add a risk for each table outside the allowed schemas and tables.
+
+    Unqualified names can't be attributed to a schema, so they are only
+    checked against allowed_tables and otherwise left to the unqualified
+    table check.
+    """
+    if allowed_schemas is None and allowed_tables is None:
+        return
 
-    # SQL operation tags
-    sql_upper = sql_snippet.upper()
+    schemas = {schema.lower() for schema in allowed_schemas or []}
+    tables = {table.lower() for table in allowed_tables or []}
 
-    if any(kw in sql_upper for kw in ["SELECT", "FETCH"]):
-        tags.append("dml")
-        tags.append("select")
+    for call in calls:
+        for table in call.tables:
+            name = table.lower()
+            schema, _, bare = name.rpartition(".")
+            if name in tables or bare in tables or schema in schemas:
+                continue
+            if not schema:
+                continue
+            call.risks.append(
+                f"Table '{table}' is outside the allowed schemas/tables - "
+                "forbidden by the access policy"
+            )
+
+
+def _flag_audit_inserts(calls: list[DBCall], audit_table_pattern: str) -> None:
+    """Add a risk for plain INSERTs into audit/event/log tables.
+
+    Replaying such an insert (retries, redelivered messages) writes a
+    duplicate row unless it is guarded by ON CONFLICT/ON DUPLICATE KEY or
+    INSERT IGNORE.
+    """
+    for call in calls:
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            insert = re.match(r"\s*INSERT\s+INTO\s+([\w.\"]+)", statement, re.IGNORECASE)
+            if not insert:
+                continue
 
-    if any(kw in sql_upper for kw in ["INSERT", "UPDATE", "DELETE"]):
-        tags.append("dml")
-        if "INSERT" in sql_upper:
-            tags.append("insert")
-        if "UPDATE" in sql_upper:
-            tags.append("update")
-        if "DELETE" in sql_upper:
-            tags.append("delete")
+            table = insert.group(1).strip('"')
+            if not re.search(audit_table_pattern, table, re.IGNORECASE):
+                continue
+            if re.search(r"\bON\s+(?:CONFLICT|DUPLICATE\s+KEY)\b", statement, re.IGNORECASE):
+                continue
 
-    if any(kw in sql_upper for kw in ["CREATE", "ALTER", "DROP"]):
-        tags.append("ddl")
-        tags.append("migrations")
+            call.risks.append(
+                f"Plain INSERT into audit/event table '{table}' - retries create duplicates; "
+                "add an idempotency key with ON CONFLICT DO NOTHING"
+            )
 
-    if any(kw in sql_upper for kw in ["BEGIN", "COMMIT", "ROLLBACK", "TRANSACTION"]):
-        tags.append("transactions")
 
-    if any(kw in sql_upper for kw in ["LOCK", "FOR UPDATE", "FOR SHARE"]):
-        tags.append("locks")
+def _discover_migration_statements(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover statements in goose, sql-migrate and golang-migrate files.
 
-    if any(kw in sql_upper for kw in ["GRANT", "REVOKE", "ROLE", "USER"]):
-        tags.append("auth-db")
+    Files are split into up/down sections by their directives (or by the
+    .up.sql/.down.sql file name for golang-migrate), and each statement is
+    analyzed on its own and tagged with its migration direction. Plain
+    .sql files without migration markers are left to the SQL schema
+    parser.
+    """
+    sections = []  # (tool, direction, section start, section end)
+
+    directives = list(MIGRATION_DIRECTIVE_PATTERN.finditer(content))
+    if directives:
+        for i, directive in enumerate(directives):
+            tool = "goose" if directive.group(1).lower() == "goose" else "sql-migrate"
+            end = directives[i + 1].start() if i + 1 < len(directives) else len(content)
+            sections.append((tool, directive.group(2).lower(), directive.end(), end))
+    else:
+        by_name = MIGRATION_FILENAME_PATTERN.search(file_path)
+        if not by_name:
+            return []
+        sections.append(("golang-migrate", by_name.group(1).lower(), 0, len(content)))
 
-    # Migration-specific
-    if call_type == "migration":
-        tags.append("migrations")
+    # golang-migrate keeps the down migration in its own file
+    has_down = not directives or any(
+        direction == "down" and _migration_section_statements(content[start:end])
+        for _, direction, start, end in sections
+    )
 
-    return list(set(tags))  # Remove duplicates
+    calls = []
+    for tool, direction, start, end in sections:
+        statements = _migration_section_statements(content[start:end])
+        created = {
+            ddl.group(1).strip('"').lower()
+            for statement, _ in statements
+            for ddl in [CREATE_TABLE_PATTERN.match(_strip_sql_comments(statement))] if ddl
+        }
+        for statement, offset in statements:
+            call = _build_call(
+                file_path, content, "sql", tool, "migration",
+                statement, start + offset, table_patterns
+            )
+            call.tags.append(f"migration-{direction}")
+            if direction == "up":
+                call.risks.extend(_migration_safety_risks(_strip_sql_comments(statement), has_down, created))
+            calls.append(call)
 
+    return calls
 
-def scan_repository_for_db_calls(
-    repo_root: Path,
-    file_list: list[dict[str, Any]]
-) -> list[DBCall]:
-    """Scan entire repository for database calls.
+
+def _migration_safety_risks(statement: str, has_down: bool, created: set[str]) -> list[str]:
+    """Check an up migration statement for destructive or locking DDL.
 
     Args:
-        repo_root: Repository root path
-        file_list: List of files with language info
+        statement: Statement text (comments removed)
+        has_down: Whether the migration has a down section with statements
+        created: Lowercased tables created in the same section, which
+            have no rows or readers to lock out yet
 
     Returns:
-        List of all discovered DB calls
+        List of risk descriptions
     """
-    all_calls = []
+    risks = []
+
+    # Routine bodies ($$ ... $$) aren't run by the migration itself
+    statement = re.sub(r"\$(\w*)\$.*?\$\1\$", " ", statement, flags=re.DOTALL)
+
+    alter = ALTER_TABLE_PATTERN.search(statement)
+    destructive = MIGRATION_DESTRUCTIVE_PATTERN.search(statement)
+    dropped = DROP_COLUMN_PATTERN.search(alter.group(2)) if alter else None
+    operation = None
+    if destructive:
+        operation = " ".join(destructive.group(0).split()).upper()
+    elif dropped:
+        operation = f"DROP COLUMN {dropped.group(1)}"
+
+    if operation:
+        if has_down:
+            risks.append(
+                f"Destructive {operation} in an up migration - the down migration can "
+                "restore the structure but not the data"
+            )
+        else:
+            risks.append(
+                f"Destructive {operation} in an up migration with no down migration - "
+                "it can't be rolled back"
+            )
+
+    index = re.match(
+        r"\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?!CONCURRENTLY\b).*?\bON\s+(?:ONLY\s+)?([\w.\"]+)",
+        statement, re.IGNORECASE | re.DOTALL
+    )
+    if index and index.group(1).strip('"').lower() not in created:
+        risks.append(
+            "CREATE INDEX without CONCURRENTLY blocks writes to the table while the index builds"
+        )
+
+    added = re.finditer(
+        r"\bADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?"
+        r"(?!(?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|EXCLUDE)\b)(\w+)([^,]*)",
+        alter.group(2) if alter else "", re.IGNORECASE
+    )
+    for column in added:
+        definition = column.group(2)
+        if re.search(r"\bNOT\s+NULL\b", definition, re.IGNORECASE) and \
+                not re.search(r"\bDEFAULT\b", definition, re.IGNORECASE):
+            risks.append(
+                f"ADD COLUMN {column.group(1)} NOT NULL without a DEFAULT fails on a table that already has rows"
+            )
+
+    if re.search(r"\bALTER\s+(?:COLUMN\s+)?\w+\s+(?:SET\s+DATA\s+)?TYPE\b", statement, re.IGNORECASE):
+        risks.append("ALTER COLUMN ... TYPE rewrites the table under an exclusive lock")
+
+    return risks
+
+
+def _migration_section_statements(section: str) -> list[tuple[str, int]]:
+    """Split a migration section into (statement, offset) pairs.
+
+    StatementBegin/StatementEnd blocks are kept whole so function bodies
+    containing semicolons stay a single statement.
+    """
+    statements = []
+    cursor = 0
+
+    def add_split(text: str, base: int) -> None:
+        search_from = 0
+        for statement in _split_statements(_strip_sql_comments(text)):
+            found = text.find(statement.split(None, 1)[0], search_from)
+            found = search_from if found == -1 else found
+            statements.append((statement, base + found))
+            search_from = found + 1
+
+    for block in MIGRATION_STATEMENT_BLOCK_PATTERN.finditer(section):
+        add_split(section[cursor:block.start()], cursor)
+        raw_body = block.group(1)
+        body = raw_body.strip().rstrip(";").strip()
+        if body:
+            leading = len(raw_body) - len(raw_body.lstrip())
+            statements.append((body, block.start(1) + leading))
+        cursor = block.end()
+
+    add_split(section[cursor:], cursor)
+    return statements
+
+
+def _build_call(
+    file_path: str,
+    content: str,
+    language: str,
+    framework: str,
+    call_type: str,
+    sql_snippet: str,
+    pos: int,
+    table_patterns: dict[str, str] | None = None
+) -> DBCall:
+    """Analyze a SQL snippet found at a position and build its DBCall."""
+    # Get line number
+    line_num = content[:pos].count("\n") + 1
+
+    # Comments are surfaced as annotations and kept out of the analysis
+    annotations = _extract_annotations(sql_snippet)
+    sql_text = _strip_sql_comments(sql_snippet)
+
+    # Template actions ({{.Table}}) can't be analyzed; keep the static skeleton
+    is_templated = TEMPLATE_ACTION_PATTERN.search(sql_text) is not None
+    if is_templated:
+        sql_text = TEMPLATE_ACTION_PATTERN.sub("{tmpl}", sql_text)
+
+    # Determine tags and referenced tables
+    tags = _determine_tags(sql_text, call_type, framework)
+    if is_templated:
+        tags.append("templated-sql")
+    tables, has_dynamic_table = _extract_tables(sql_text, table_patterns)
+    if has_dynamic_table:
+        tags.append("dynamic-table")
+
+    write_tables = _extract_write_tables(sql_text, table_patterns)
+    if _has_multi_table_write(sql_text):
+        tags.append("multi-table-write")
+
+    upsert = _extract_upsert(sql_text)
+    if upsert:
+        tags.append("upsert")
+
+    routines = _extract_routine_calls(sql_text)
+    if routines:
+        tags.append("routine-call")
+
+    having = HAVING_PATTERN.search(sql_text)
+
+    scope = _enclosing_function(content, pos, language)
+    risks = _detect_risks(sql_text, scope, call_type, language)
+
+    lock_tables = _extract_lock_tables(sql_text, tables, table_patterns)
+    if len(lock_tables) > 1:
+        risks.append(
+            f"Joined lock order: FOR UPDATE/SHARE locks rows in {' then '.join(lock_tables)} "
+            "as the join is read - transactions locking these tables must use the same order"
+        )
+
+    # Builder-formatted queries interpolate their arguments into the text;
+    # identifier positions are reported by the identifier check below
+    verbs = _value_interpolations(sql_text, language, table_patterns) if framework == "fmt.Fprintf" else 0
+    if verbs:
+        risks.append(
+            f"Query text built with fmt.Fprintf interpolating {verbs} value(s) - injection risk "
+            "unless they are constants; bind them as parameters"
+        )
+
+    func_pattern = FUNC_NAME_PATTERNS.get(language)
+    func_match = func_pattern.match(scope.lstrip()) if func_pattern else None
+
+    identifiers = _interpolated_identifiers(sql_text, language, table_patterns)
+    if identifiers and func_match and _takes_string_input(scope, language):
+        risks.append(
+            f"Identifier injection: {' and '.join(identifiers)} name interpolated from "
+            f"{func_match.group(1)}'s string input - identifiers can't be bound; check them against an allowlist"
+        )
+    elif identifiers:
+        risks.append(
+            f"Dynamic identifier: {' and '.join(identifiers)} name interpolated into the query - "
+            "make sure it comes from a fixed set of names"
+        )
+
+    # A builder fragment may get its bind parameters from later writes,
+    # and an interpolated identifier is already reported above
+    if (
+        func_match and call_type not in ("migration", "query-builder") and not identifiers
+        and _ignores_string_input(sql_text, scope, language)
+    ):
+        risks.append(
+            f"No bind parameters although {func_match.group(1)} takes string input - "
+            "check the input isn't ignored or interpolated into the query"
+        )
+
+    return DBCall(
+        file_path=file_path,
+        start_line=line_num,
+        end_line=line_num + sql_snippet.count("\n"),
+        language=language,
+        framework=framework,
+        sql_snippet=sql_snippet[:500],  # Limit length
+        call_type=call_type,
+        tags=tags,
+        tables=tables,
+        risks=risks,
+        annotations=annotations,
+        query_name=_annotation_query_name(annotations),
+        upsert=upsert,
+        function=func_match.group(1) if func_match else None,
+        write_tables=write_tables,
+        routines=routines,
+        having=having.group(1).strip() if having else None,
+        lock_tables=lock_tables
+    )
+
+
+def _discover_sqlc_queries(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover queries embedded in a sqlc-generated Go file.
 
-    for file_info in file_list:
-        file_path = repo_root / file_info["path"]
-        language = file_info["language"]
+    sqlc emits each query as a constant starting with its "-- name:"
+    annotation. The source .sql file from the "// source:" header is
+    recorded as an annotation so results can be traced back to it; the
+    generated copies carry no risks, since the source file is analyzed
+    on its own (see _discover_sqlc_source_queries).
+    """
+    calls = []
+    source = SQLC_SOURCE_PATTERN.search(content)
+
+    for match in SQLC_QUERY_PATTERN.finditer(content):
+        call = _build_call(
+            file_path, content, "go", "sqlc", "query",
+            match.group(1).strip(), match.start(), table_patterns
+        )
+        call.tags.append("generated")
+        if source:
+            call.annotations.append(f"source: {source.group(1)}")
+        calls.append(call)
 
-        # Only scan supported languages
-        if language not in ("javascript", "typescript", "python", "go", "java"):
-            continue
+    return calls
 
-        try:
-            content = file_path.read_text(encoding="utf-8", errors="ignore")
-            calls = discover_db_calls(str(file_path), content, language)
-            all_calls.extend(calls)
-        except Exception:
-            # Skip files that can't be read
+
+def _discover_sqlc_source_queries(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover the named queries of a sqlc source .sql file.
+
+    Each query runs from its "-- name: X :kind" annotation to the next
+    one, so findings point at the statement in the file sqlc generates
+    from rather than at the generated Go code.
+    """
+    calls = []
+    names = list(SQLC_NAME_PATTERN.finditer(content))
+
+    for i, name in enumerate(names):
+        end = names[i + 1].start() if i + 1 < len(names) else len(content)
+        statement = content[name.start():end].strip()
+        calls.append(_build_call(
+            file_path, content, "sql", "sqlc", "query",
+            statement, name.start(), table_patterns
+        ))
+
+    return calls
+
+
+def _discover_migration_slices(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover migrations kept as a Go []string of SQL statements.
+
+    A slice counts as migrations when at least two of its elements start
+    with CREATE/ALTER/DROP/INSERT, which keeps ordinary string slices out.
+    Each element is analyzed as its own migration statement.
+    """
+    calls = []
+
+    for match in STRING_SLICE_PATTERN.finditer(content):
+        elements = []
+        pos = match.end()
+        while True:
+            while pos < len(content) and content[pos] in " \t\r\n,":
+                pos += 1
+            literal = GO_STRING_LITERAL_PATTERN.match(content, pos)
+            if not literal:
+                break
+            text = literal.group(1) if literal.group(1) is not None else literal.group(2)
+            elements.append((text.strip(), literal.start()))
+            pos = literal.end()
+
+        if sum(1 for text, _ in elements if MIGRATION_ELEMENT_PATTERN.match(text)) < 2:
             continue
 
-    return all_calls
+        for text, start in elements:
+            if not text:
+                continue
+            calls.append(_build_call(
+                file_path, content, "go", "migration-slice", "migration",
+                text, start, table_patterns
+            ))
 
+    return calls
 
-def summarize_db_calls(calls: list[DBCall]) -> dict[str, Any]:
-    """Summarize discovered database calls.
 
-    Args:
-        calls: List of DB calls
+def _discover_pgx_batches(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover statements queued on a pgx.Batch.
 
-    Returns:
-        Summary statistics
+    Each batch.Queue(sql, args...) becomes its own call tied to the batch
+    by function and variable name, so group_batches can reassemble what
+    SendBatch runs together. The number of arguments is checked against
+    the statement's $n placeholders.
     """
-    total = len(calls)
+    calls = []
 
-    # Count by language
-    by_language = {}
-    for call in calls:
-        by_language[call.language] = by_language.get(call.language, 0) + 1
+    for match in BATCH_QUEUE_PATTERN.finditer(content):
+        quote_end = content.find(match.group(2), match.end())
+        if quote_end == -1:
+            continue
 
-    # Count by framework
-    by_framework = {}
-    for call in calls:
-        by_framework[call.framework] = by_framework.get(call.framework, 0) + 1
+        sql_snippet = content[match.end():quote_end].strip()
+        call = _build_call(
+            file_path, content, "go", "pgx", "batch",
+            sql_snippet, match.start(), table_patterns
+        )
+        call.tags.append("batch")
 
-    # Count by call type
-    by_type = {}
-    for call in calls:
-        by_type[call.call_type] = by_type.get(call.call_type, 0) + 1
+        call.batch = f"{call.function or ''}.{match.group(1)}"
 
-    # Collect unique tags
-    all_tags = set()
-    for call in calls:
-        all_tags.update(call.tags)
+        rest = content[quote_end + 1:]
+        arg_count = len(_split_top_level(rest[:_closing_paren(rest)], ","))
+        placeholders = max((int(n) for n in re.findall(r"\$(\d+)", sql_snippet)), default=0)
+        if arg_count != placeholders:
+            call.risks.append(
+                f"Queued statement uses {placeholders} placeholder(s) but is given "
+                f"{arg_count} argument(s)"
+            )
 
-    return {
-        "total_calls": total,
-        "by_language": by_language,
-        "by_framework": by_framework,
-        "by_type": by_type,
-        "unique_tags": sorted(list(all_tags)),
-        "sample_calls": [
-            {
-                "file": call.file_path,
-                "line": call.start_line,
-                "framework": call.framework,
-                "type": call.call_type,
-                "snippet": call.sql_snippet[:100]  # First 100 chars
-            }
+        calls.append(call)
+
+    return calls
+
+
+def _discover_builder_queries(
+    file_path: str,
+    content: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover queries assembled with WriteString and run as sb.String().
+
+    The WriteString calls on the builder since its last Reset() in the
+    same function are folded into one query. A non-literal write becomes
+    a %v marker, so it is analyzed like any other interpolation and the
+    call is tagged dynamic-query. Builders written with fmt.Fprintf are
+    left to the Fprintf pattern.
+    """
+    calls = []
+
+    for match in BUILDER_QUERY_PATTERN.finditer(content):
+        builder = re.escape(match.group(2))
+        scope = _enclosing_function(content, match.start(), "go")
+        scope_start = content.rfind(scope, 0, match.start() + len(scope))
+        before = content[scope_start:match.start()]
+
+        resets = list(re.finditer(rf"\b{builder}\.Reset\s*\(\s*\)", before))
+        if resets:
+            before = before[resets[-1].end():]
+        if re.search(rf"\bfmt\.Fprintf?\s*\(\s*&?{builder}\s*,", before):
+            continue
+
+        parts = []
+        is_dynamic = False
+        for write in re.finditer(rf"\b{builder}\.WriteString\s*\(", before):
+            rest = before[write.end():]
+            argument = rest[:_closing_paren(rest)].strip()
+            literal = GO_STRING_LITERAL_PATTERN.fullmatch(argument)
+            if literal:
+                parts.append(literal.group(1) if literal.group(1) is not None else literal.group(2))
+            else:
+                parts.append("%v")
+                is_dynamic = True
+
+        if not parts:
+            continue
+
+        call = _build_call(
+            file_path, content, "go", "strings.Builder",
+            "execute" if match.group(1) == "Exec" else "query",
+            "".join(parts).strip(), match.start(), table_patterns
+        )
+        if is_dynamic:
+            call.tags.append("dynamic-query")
+        calls.append(call)
+
+    return calls
+
+
+def _discover_query_wrappers(
+    file_path: str,
+    content: str,
+    language: str,
+    query_wrappers: dict[str, int],
+    table_patterns: dict[str, str] | None = None
+) -> list[DBCall]:
+    """Discover calls to registered query wrapper functions.
+
+    The SQL is read from the configured argument; calls where that
+    argument isn't a string literal are skipped.
+    """
+    calls = []
+
+    for name, sql_index in query_wrappers.items():
+        for match in re.finditer(rf"(?<![\w.]){re.escape(name)}\s*\(|\.{re.escape(name)}\s*\(", content):
+            arg_pos = _argument_position(content, match.end(), sql_index)
+            if arg_pos is None or content[arg_pos] not in "\"'`":
+                continue
+
+            sql_snippet = _extract_sql_snippet(content, arg_pos, language)
+            calls.append(_build_call(
+                file_path, content, language, name, "query",
+                sql_snippet, match.start(), table_patterns
+            ))
+
+    return calls
+
+
+def _argument_position(content: str, pos: int, index: int) -> int | None:
+    """Return where the index-th argument of a call starts.
+
+    pos is just after the call's opening paren. Nested brackets and
+    string literals are skipped; returns None if the call has fewer
+    arguments.
+    """
+    depth = 0
+    quote = None
+    current = 0
+    i = pos
+
+    while i < len(content):
+        if current == index:
+            while i < len(content) and content[i].isspace():
+                i += 1
+            return i if i < len(content) and content[i] != ")" else None
+
+        ch = content[i]
+        if quote:
+            if ch == "\\" and quote != "`":
+                i += 1
+            elif ch == quote:
+                quote = None
+        elif ch in "\"'`":
+            quote = ch
+        elif ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            if depth == 0:
+                return None
+            depth -= 1
+        elif ch == "," and depth == 0:
+            current += 1
+        i += 1
+
+    return None
+
+
+def _go_exec_framework(content: str, pos: int) -> str:
+    """Tell whether a db.Exec call is on a GORM or a database/sql handle.
+
+    Files importing only one of them decide by import. When both are
+    imported, a gorm.Open or *gorm.DB in the enclosing function makes it
+    GORM.
+    """
+    if "gorm.io/gorm" not in content:
+        return "database/sql"
+    if '"database/sql"' not in content:
+        return "gorm"
+    scope = _enclosing_function(content, pos, "go")
+    return "gorm" if re.search(r"\bgorm\.(?:Open|DB)\b", scope) else "database/sql"
+
+
+def _discover_sql_begin(file_path: str, content: str) -> list[DBCall]:
+    """Discover database/sql transactions started with db.Begin().
+
+    Begin() ignores cancellation; when a context is already available in
+    the function, BeginTx(ctx, opts) should be used instead.
+    """
+    calls = []
+
+    for match in SQL_BEGIN_PATTERN.finditer(content):
+        scope = _enclosing_function(content, match.start(), "go")
+        func_match = FUNC_NAME_PATTERNS["go"].match(scope.lstrip())
+        line_num = content[:match.start()].count("\n") + 1
+
+        # Only a context declared before the Begin() call counts
+        risks = []
+        scope_start = content.rfind(scope, 0, match.start() + len(scope))
+        if GO_CONTEXT_PATTERN.search(content[scope_start:match.start()]):
+            risks.append(
+                "database/sql Begin() ignores the context in scope - use BeginTx(ctx, opts) "
+                "to respect cancellation and set an explicit isolation level"
+            )
+
+        calls.append(DBCall(
+            file_path=file_path,
+            start_line=line_num,
+            end_line=line_num,
+            language="go",
+            framework="database/sql",
+            sql_snippet="",
+            call_type="transaction",
+            tags=["database", "db-database/sql", "transactions"],
+            risks=risks,
+            function=func_match.group(1) if func_match else None,
+            kind="finding"
+        ))
+
+    return calls
+
+
+def _discover_empty_transactions(file_path: str, content: str) -> list[DBCall]:
+    """Discover Go transactions committed without running any statements.
+
+    A transaction counts as empty when its handle isn't used between the
+    Begin and the Commit other than to roll back, so handing it to a
+    helper keeps it out.
+    """
+    calls = []
+
+    for match in GO_TX_BEGIN_PATTERN.finditer(content):
+        tx = re.escape(match.group(1))
+        scope = _enclosing_function(content, match.start(), "go")
+        scope_start = content.rfind(scope, 0, match.start() + len(scope))
+        rest = content[match.end():scope_start + len(scope)]
+        args = rest[:_closing_paren(rest)]
+
+        commit = re.search(rf"\b{tx}\.Commit\s*\(", rest)
+        if not commit or re.search(rf"\b{tx}\b(?!\.(?:Commit|Rollback)\b)", rest[len(args):commit.start()]):
+            continue
+
+        if "gorm.io/gorm" in content:
+            framework = "gorm"
+        elif (match.group(2) == "Begin" and args.strip()) or "pgx." in args:
+            framework = "pgx"
+        else:
+            framework = "database/sql"
+
+        func_match = FUNC_NAME_PATTERNS["go"].match(scope.lstrip())
+        line_num = content[:match.start()].count("\n") + 1
+        commit_line = content[:match.end() + commit.start()].count("\n") + 1
+        calls.append(DBCall(
+            file_path=file_path,
+            start_line=line_num,
+            end_line=commit_line,
+            language="go",
+            framework=framework,
+            sql_snippet="",
+            call_type="transaction",
+            tags=["database", f"db-{framework}", "transactions"],
+            risks=[
+                f"Empty transaction: {match.group(1)} is committed at line {commit_line} without "
+                "running any statements - remove it or move the missing work into it"
+            ],
+            function=func_match.group(1) if func_match else None,
+            kind="finding"
+        ))
+
+    return calls
+
+
+def _discover_gorm_struct_where(file_path: str, content: str) -> list[DBCall]:
+    """Discover GORM conditions passed as struct literals.
+
+    GORM builds struct conditions from non-zero fields only, so
+    Where(&User{Username: name}) matches every user when name is empty.
+    """
+    calls = []
+
+    for match in GORM_STRUCT_WHERE_PATTERN.finditer(content):
+        scope = _enclosing_function(content, match.start(), "go")
+        func_match = FUNC_NAME_PATTERNS["go"].match(scope.lstrip())
+        line_num = content[:match.start()].count("\n") + 1
+
+        calls.append(DBCall(
+            file_path=file_path,
+            start_line=line_num,
+            end_line=line_num,
+            language="go",
+            framework="gorm",
+            sql_snippet="",
+            call_type="query",
+            tags=["database", "db-gorm"],
+            risks=[
+                f"GORM {match.group(1)} with a {match.group(2)} struct ignores zero-value fields - "
+                "an empty input drops the condition; use a map or a \"column = ?\" condition"
+            ],
+            function=func_match.group(1) if func_match else None,
+            kind="finding"
+        ))
+
+    return calls
+
+
+def group_batches(calls: list[DBCall]) -> list[BatchInfo]:
+    """Group queued pgx.Batch statements into one BatchInfo per batch.
+
+    Args:
+        calls: Discovered DB calls
+
+    Returns:
+        Batches in order of first queued statement
+    """
+    batches: dict[tuple[str, str], BatchInfo] = {}
+
+    for call in calls:
+        if not call.batch:
+            continue
+
+        function, _, variable = call.batch.partition(".")
+        key = (call.file_path, call.batch)
+        if key not in batches:
+            batches[key] = BatchInfo(
+                file_path=call.file_path,
+                function=function,
+                variable=variable,
+                start_line=call.start_line,
+                queries=[],
+                tables=[]
+            )
+
+        batch = batches[key]
+        batch.queries.append(call)
+        for table in call.tables:
+            if table not in batch.tables:
+                batch.tables.append(table)
+
+    return list(batches.values())
+
+
+def _extract_sql_snippet(content: str, start_pos: int, language: str) -> str:
+    """Extract SQL snippet from match position.
+
+    Args:
+        content: File content
+        start_pos: Match start position
+        language: Programming language
+
+    Returns:
+        Extracted SQL snippet
+    """
+    # Find the opening quote after the match
+    quote_chars = ['"', "'", '`']
+    quote_start = None
+    quote_char = None
+
+    for i in range(start_pos, min(start_pos + 100, len(content))):
+        if content[i] in quote_chars:
+            quote_start = i
+            quote_char = content[i]
+            break
+
+    if not quote_start:
+        return ""
+
+    # Find closing quote (simple - doesn't handle escapes perfectly)
+    quote_end = content.find(quote_char, quote_start + 1)
+    if quote_end == -1:
+        return ""
+
+    snippet = content[quote_start + 1:quote_end]
+
+    # For template literals in JS/TS, handle multiline
+    if language in ("javascript", "typescript") and quote_char == '`':
+        # Extract up to 500 chars or closing backtick
+        max_end = min(quote_start + 500, len(content))
+        for i in range(quote_start + 1, max_end):
+            if content[i] == '`' and (i == 0 or content[i-1] != '\\'):
+                snippet = content[quote_start + 1:i]
+                break
+
+    return snippet.strip()
+
+
+def _extract_annotations(sql_snippet: str) -> list[str]:
+    """Extract leading -- and /* */ comments from a SQL snippet.
+
+    Structured comments such as sqlc's "-- name: GetUser :one" or
+    "/* app:orders */" are returned with comment markers removed.
+    """
+    annotations = []
+    rest = sql_snippet.lstrip()
+
+    while True:
+        if rest.startswith("--"):
+            line_end = rest.find("\n")
+            comment = rest[2:] if line_end == -1 else rest[2:line_end]
+            rest = "" if line_end == -1 else rest[line_end + 1:]
+        elif rest.startswith("/*"):
+            block_end = rest.find("*/")
+            if block_end == -1:
+                break
+            comment = rest[2:block_end]
+            rest = rest[block_end + 2:]
+        else:
+            break
+
+        comment = comment.strip()
+        if comment:
+            annotations.append(comment)
+        rest = rest.lstrip()
+
+    return annotations
+
+
+def _annotation_query_name(annotations: list[str]) -> str | None:
+    """Return the query name from a "name: X" annotation, if any."""
+    for annotation in annotations:
+        match = re.match(r"name:\s*(\w+)", annotation, re.IGNORECASE)
+        if match:
+            return match.group(1)
+    return None
+
+
+def _strip_sql_comments(sql: str) -> str:
+    """Remove -- and /* */ comments from SQL, leaving string literals intact."""
+    result = []
+    i = 0
+    length = len(sql)
+
+    while i < length:
+        char = sql[i]
+
+        if char == "'":
+            end = sql.find("'", i + 1)
+            end = length - 1 if end == -1 else end
+            result.append(sql[i:end + 1])
+            i = end + 1
+        elif sql.startswith("--", i):
+            end = sql.find("\n", i)
+            i = length if end == -1 else end
+        elif sql.startswith("/*", i):
+            end = sql.find("*/", i + 2)
+            result.append(" ")
+            i = length if end == -1 else end + 2
+        else:
+            result.append(char)
+            i += 1
+
+    return "".join(result)
+
+
+def _detect_dialect(content: str) -> str | None:
+    """Detect the SQL dialect from the drivers a file imports.
+
+    Returns None when no driver is recognized or drivers for more than
+    one dialect are present.
+    """
+    found = [
+        dialect for dialect, markers in DIALECT_MARKERS.items()
+        if any(re.search(marker, content, re.MULTILINE) for marker in markers)
+    ]
+    return found[0] if len(found) == 1 else None
+
+
+def _detect_dialect_risks(sql_snippet: str, dialect: str | None) -> list[str]:
+    """Flag SQL features the detected dialect doesn't support."""
+    risks = []
+
+    for pattern, feature in DIALECT_UNSUPPORTED_FEATURES.get(dialect, []):
+        if re.search(pattern, sql_snippet, re.IGNORECASE):
+            risks.append(f"Uses {feature}, which {dialect} does not support - dialect mismatch")
+
+    return risks
+
+
+def _find_reserved_identifiers(sql_snippet: str, dialect: str | None) -> list[str]:
+    """Find reserved words used as unquoted table or column identifiers.
+
+    Looks at referenced table names, CREATE TABLE column definitions and
+    INSERT column lists; quoted identifiers are accepted.
+    """
+    reserved = RESERVED_WORDS.get(dialect)
+    if not reserved:
+        return []
+
+    identifiers = []
+
+    for match in TABLE_REF_PATTERN.finditer(sql_snippet):
+        identifiers.extend(match.group(2).split("."))
+
+    create = re.search(r"\bCREATE\s+TABLE\b[^(]*\(", sql_snippet, re.IGNORECASE)
+    if create:
+        body = sql_snippet[create.end():]
+        for column_def in _split_top_level(body[:_closing_paren(body)], ","):
+            parts = column_def.split()
+            if parts and parts[0].upper() not in (
+                "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "CONSTRAINT", "EXCLUDE"
+            ):
+                identifiers.append(parts[0])
+
+    insert = re.search(r"\bINSERT\s+INTO\s+[\w.\"`]+\s*\(([^)]*)\)", sql_snippet, re.IGNORECASE)
+    if insert:
+        identifiers.extend(col.strip() for col in insert.group(1).split(","))
+
+    found = []
+    for identifier in identifiers:
+        word = identifier.strip()
+        if word.lower() in reserved and word.lower() not in found:
+            found.append(word.lower())
+
+    return found
+
+
+def _closing_paren(text: str) -> int:
+    """Index of the parenthesis closing an already-open group (len(text) if unbalanced).
+
+    Parentheses inside quoted strings don't count (see _split_top_level).
+    """
+    depth = 1
+    quote = None
+    escaped = False
+    for i, char in enumerate(text):
+        if quote:
+            if escaped:
+                escaped = False
+            elif char == "\\" and quote == '"':
+                escaped = True
+            elif char == quote:
+                quote = None
+        elif char in "\"'`":
+            quote = char
+        elif char == "(":
+            depth += 1
+        elif char == ")":
+            depth -= 1
+            if depth == 0:
+                return i
+    return len(text)
+
+
+def _split_top_level(text: str, delimiter: str) -> list[str]:
+    """Split text on a delimiter outside brackets and quoted strings.
+
+    (), [] and {} nest, so Go composite literals and index expressions
+    stay whole. '...', "..." and `...` strings are skipped; backslash
+    escapes apply only inside "..." (Go strings), since SQL doubles its
+    quotes instead.
+    """
+    parts = []
+    depth = 0
+    quote = None
+    escaped = False
+    start = 0
+
+    for i, char in enumerate(text):
+        if quote:
+            if escaped:
+                escaped = False
+            elif char == "\\" and quote == '"':
+                escaped = True
+            elif char == quote:
+                quote = None
+        elif char in "\"'`":
+            quote = char
+        elif char in "([{":
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+        elif char == delimiter and depth == 0:
+            parts.append(text[start:i])
+            start = i + 1
+
+    parts.append(text[start:])
+    return [part.strip() for part in parts if part.strip()]
+
+
+def _enclosing_function(content: str, pos: int, language: str) -> str:
+    """Return the source of the function surrounding a position.
+
+    Functions start at a line beginning with func (Go, methods included)
+    or def (Python, indented methods and nested functions included). A
+    Go function ends at its closing brace in column 0; a Python one at
+    the next def/class/decorator indented no deeper. Positions outside
+    any function get the module-level text around them, and languages
+    without a simple marker use the whole file.
+    """
+    pattern = FUNCTION_START_PATTERNS.get(language)
+    if not pattern:
+        return content
+
+    starts = list(pattern.finditer(content))
+    previous_end = 0
+    for match in reversed([m for m in starts if m.start() <= pos]):
+        end = _function_end(content, match, language)
+        if pos < end:
+            return content[match.start():end]
+        previous_end = max(previous_end, end)
+
+    following = next((m.start() for m in starts if m.start() > pos), len(content))
+    return content[previous_end:following]
+
+
+def _function_end(content: str, start: re.Match, language: str) -> int:
+    """Return the end offset of the function starting at a FUNCTION_START_PATTERNS match."""
+    if language == "go":
+        closing = re.compile(r"^}[^\n]*", re.MULTILINE).search(content, start.end())
+        return closing.end() if closing else len(content)
+
+    indent = len(start.group(1).expandtabs())
+    for match in PYTHON_BLOCK_START_PATTERN.finditer(content, start.end()):
+        if len(match.group(1).expandtabs()) <= indent:
+            return match.start()
+    return len(content)
+
+
+def _detect_risks(sql_snippet: str, scope: str, call_type: str, language: str) -> list[str]:
+    """Detect risky query patterns.
+
+    Args:
+        sql_snippet: SQL code snippet (comments removed)
+        scope: Source of the enclosing function
+        call_type: Type of call
+        language: Programming language
+
+    Returns:
+        List of risk descriptions
+    """
+    risks = []
+
+    statement_count = len(_split_statements(sql_snippet))
+    if statement_count > 1 and call_type != "migration":
+        risks.append(
+            f"{statement_count} statements in a single call - "
+            "possible statement smuggling or accidental multi-statement execution"
+        )
+
+    if _has_dynamic_limit(sql_snippet, language):
+        risks.append(
+            "LIMIT/OFFSET value interpolated into the query text - "
+            "bind it as a parameter to avoid injection"
+        )
+
+    for join in _find_cartesian_joins(sql_snippet):
+        risks.append(f"Cartesian join: {join} - add a join condition")
+
+    for statement in _split_statements(sql_snippet):
+        # Routine bodies ($$ ... $$) use $n for their own arguments
+        ddl_text = re.sub(r"\$(\w*)\$.*?\$\1\$", " ", statement, flags=re.DOTALL)
+        if call_type != "migration" and DDL_START_PATTERN.match(ddl_text) and _placeholder_styles(ddl_text):
+            risks.append(
+                "Parameterized DDL: CREATE/ALTER/DROP can't take bind parameters - "
+                "the statement fails at runtime; validate and quote identifiers or literals instead"
+            )
+
+    if _has_blocking_lock(sql_snippet, scope):
+        risks.append(
+            "Row lock without NOWAIT/SKIP LOCKED and no lock or statement timeout - "
+            "can block indefinitely"
+        )
+
+    if re.search(r"\bORDER\s+BY\s+(?:RANDOM|RAND)\s*\(\s*\)", sql_snippet, re.IGNORECASE):
+        risks.append(
+            "ORDER BY random() sorts every candidate row - use TABLESAMPLE or "
+            "pick a random key range instead"
+        )
+
+    for column in _ungrouped_having_columns(sql_snippet):
+        risks.append(
+            f"HAVING references '{column}', which is neither aggregated nor in GROUP BY - "
+            "move the condition to WHERE or aggregate it"
+        )
+
+    if _has_lock_queue_hazard(sql_snippet):
+        risks.append(
+            "Locks the first row by ORDER BY/LIMIT without SKIP LOCKED - concurrent "
+            "workers queue on the same row; use FOR UPDATE SKIP LOCKED"
+        )
+
+    return risks
+
+
+def _placeholder_risks(sql_snippet: str, dialect: str | None) -> list[str]:
+    """Flag statements mixing placeholder styles the driver can't bind together.
+
+    A dialect's registered styles bind together (SQLite takes ? and :name
+    in one statement); without a dialect any mix is flagged.
+    """
+    risks = []
+    bound = DIALECT_PLACEHOLDER_STYLES.get(dialect)
+
+    for statement in _split_statements(sql_snippet):
+        styles = _placeholder_styles(statement)
+        if len(styles) < 2:
+            continue
+        if bound is None:
+            risks.append(
+                f"Mixed placeholder styles ({', '.join(styles)}) in one statement - "
+                "the driver binds only one of them"
+            )
+        elif any(style not in bound for style in styles):
+            risks.append(
+                f"Mixed placeholder styles ({', '.join(styles)}) in one statement - "
+                f"{dialect} binds only {', '.join(bound)}"
+            )
+
+    return risks
+
+
+def _placeholder_styles(statement: str) -> list[str]:
+    """Bind placeholder styles used in a statement, ignoring string literals."""
+    unquoted = re.sub(r"'(?:[^']|'')*'", "''", statement)
+    return [style for style, pattern in PLACEHOLDER_STYLES.items() if pattern.search(unquoted)]
+
+
+def _has_dynamic_limit(sql_snippet: str, language: str) -> bool:
+    """Check for LIMIT/OFFSET values built by formatting or concatenation.
+
+    Matches template placeholders after LIMIT/OFFSET, fmt verbs in Go
+    (Python DB-API drivers bind %s), and a snippet ending in LIMIT/OFFSET
+    (the value is concatenated after the closing quote). Bound parameters
+    ($1, ?, :name) are fine.
+    """
+    verbs = r"%[dsv]|" if language == "go" else ""
+    return re.search(
+        rf"\b(?:LIMIT|OFFSET)\s*(?:{verbs}\$?\{{|$)",
+        sql_snippet.rstrip(),
+        re.IGNORECASE
+    ) is not None
+
+
+def _find_cartesian_joins(sql_snippet: str) -> list[str]:
+    """Find joins that produce a cartesian product.
+
+    Flags JOINs without ON/USING (CROSS and NATURAL joins are explicit)
+    and comma-separated FROM tables that no WHERE predicate links.
+
+    Returns:
+        Descriptions of the unconstrained joins
+    """
+    joins = []
+
+    for match in re.finditer(
+        r"(\w+\s+)?\bJOIN\s+(?!LATERAL\b|\()([\w.\"]+)(?:\s+(?:AS\s+)?(?!ON\b|USING\b)(\w+))?",
+        sql_snippet,
+        re.IGNORECASE
+    ):
+        if match.group(1) and match.group(1).strip().upper() in ("CROSS", "NATURAL"):
+            continue
+        following = sql_snippet[match.end():].lstrip()
+        if not re.match(r"(?:ON|USING)\b", following, re.IGNORECASE):
+            joins.append(f"JOIN {match.group(2)} without ON/USING")
+
+    for from_match in re.finditer(r"\bFROM\s+", sql_snippet, re.IGNORECASE):
+        entries = _parse_from_list(sql_snippet[from_match.end():])
+        if len(entries) < 2:
+            continue
+
+        where = re.search(
+            r"\bWHERE\b(.*?)(?:\bGROUP\b|\bORDER\b|\bLIMIT\b|\bFOR\b|\bUNION\b|$)",
+            sql_snippet[from_match.end():],
+            re.IGNORECASE | re.DOTALL
+        )
+        where_text = where.group(1) if where else ""
+
+        for table, alias in entries:
+            others = [a for t, a in entries if a != alias]
+            linked = any(
+                re.search(
+                    rf"\b{re.escape(alias)}\.\w+\s*=\s*{re.escape(other)}\.\w+|"
+                    rf"\b{re.escape(other)}\.\w+\s*=\s*{re.escape(alias)}\.\w+",
+                    where_text,
+                    re.IGNORECASE
+                )
+                for other in others
+            )
+            if not linked:
+                joins.append(f"{table} in FROM list not linked to other tables by WHERE")
+
+    return joins
+
+
+def _has_blocking_lock(sql_snippet: str, scope: str) -> bool:
+    """Check for FOR UPDATE/FOR SHARE that may wait forever on a lock."""
+    lock = re.search(
+        r"\bFOR\s+(?:NO\s+KEY\s+)?(?:UPDATE|SHARE)\b(?P<rest>[^;]*)",
+        sql_snippet,
+        re.IGNORECASE
+    )
+    if not lock:
+        return False
+
+    if re.search(r"\bNOWAIT\b|\bSKIP\s+LOCKED\b", lock.group("rest"), re.IGNORECASE):
+        return False
+
+    return LOCK_TIMEOUT_PATTERN.search(scope) is None
+
+
+def _ungrouped_having_columns(sql_snippet: str) -> list[str]:
+    """Columns a HAVING clause uses outside aggregates that aren't grouped."""
+    columns = []
+
+    for statement in _split_statements(sql_snippet):
+        having = HAVING_PATTERN.search(statement)
+        if not having:
+            continue
+
+        group_by = GROUP_BY_PATTERN.search(statement[:having.start()])
+        grouped = set()
+        if group_by:
+            for item in _split_top_level(group_by.group(1), ","):
+                grouped.add(item.strip().strip('"').lower())
+                grouped.add(item.strip().rsplit(".", 1)[-1].strip('"').lower())
+
+        # Drop aggregate calls and literals; what's left must be grouped
+        text = re.sub(r"'(?:[^']|'')*'", "''", having.group(1))
+        while True:
+            aggregate = re.search(
+                r"\b(" + "|".join(AGGREGATE_FUNCTIONS) + r")\s*\(", text, re.IGNORECASE
+            )
+            if not aggregate:
+                break
+            rest = text[aggregate.end():]
+            text = text[:aggregate.start()] + " " + rest[_closing_paren(rest) + 1:]
+
+        for match in re.finditer(r"(?<![\w$:.])((?:[A-Za-z_]\w*\.)?[A-Za-z_]\w*)(?!\s*\()", text):
+            name = match.group(1)
+            bare = name.rsplit(".", 1)[-1].lower()
+            if bare in HAVING_KEYWORDS or name.lower() in grouped or bare in grouped:
+                continue
+            if name not in columns:
+                columns.append(name)
+
+    return columns
+
+
+def _takes_string_input(scope: str, language: str) -> bool:
+    """Check whether the enclosing function has string parameters.
+
+    Go parameters typed string/interface{}/any and Python parameters
+    annotated str count as input.
+    """
+    signature = SIGNATURE_PATTERNS[language].match(scope.lstrip()) if language in SIGNATURE_PATTERNS else None
+    return bool(signature and STRING_PARAM_PATTERNS[language].search(signature.group(1)))
+
+
+def _interpolated_identifiers(
+    sql_snippet: str,
+    language: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[str]:
+    """Find identifier positions filled in by string interpolation.
+
+    Tables after FROM/JOIN/INTO/UPDATE/TABLE and columns in select lists,
+    ORDER/GROUP BY and the left side of comparisons count; interpolated
+    values and tables mapped by table_patterns don't.
+
+    Returns:
+        The kinds of identifiers interpolated ("table", "column")
+    """
+    marker = IDENTIFIER_MARKERS.get(language, IDENTIFIER_MARKERS["default"])
+    kinds = []
+
+    for match in TABLE_REF_PATTERN.finditer(sql_snippet):
+        name = match.group(2)
+        if re.search(marker, name) and _normalize_table(name.strip('"'), table_patterns) is None:
+            kinds.append("table")
+            break
+
+    column_positions = (
+        rf"\b(?:ORDER|GROUP)\s+BY\s+(?:[\w.\"]+(?:\s+(?:ASC|DESC))?\s*,\s*)*{marker}",
+        rf"\bSELECT\s+(?:DISTINCT\s+)?(?:[\w.\"]+\s*,\s*)*{marker}\s*(?:,|\bFROM\b)",
+        rf"\b(?:WHERE|AND|OR|ON)\s+{marker}\s*(?:=|<>|!=|<=|>=|<|>|\bI?LIKE\b|\bIN\b|\bIS\b)",
+    )
+    if any(re.search(pattern, sql_snippet, re.IGNORECASE) for pattern in column_positions):
+        kinds.append("column")
+
+    return kinds
+
+
+def _value_interpolations(
+    sql_snippet: str,
+    language: str,
+    table_patterns: dict[str, str] | None = None
+) -> int:
+    """Count fmt verbs interpolating values rather than identifiers.
+
+    Each verb is checked on its own, with the other verbs replaced by a
+    constant, so a query mixing both kinds counts only its values.
+    """
+    count = 0
+
+    for verb in FORMAT_VERB_PATTERN.finditer(sql_snippet):
+        text = FORMAT_VERB_PATTERN.sub(
+            lambda other: other.group(0) if other.start() == verb.start() else "0",
+            sql_snippet
+        )
+        if not _interpolated_identifiers(text, language, table_patterns):
+            count += 1
+
+    return count
+
+
+def _ignores_string_input(sql_snippet: str, scope: str, language: str) -> bool:
+    """Check for a query without bind parameters in a function taking string input."""
+    if not _takes_string_input(scope, language):
+        return False
+
+    # Only queries count; DDL, maintenance and connection strings are constant
+    if not re.match(r"\s*(?:SELECT|INSERT|UPDATE|DELETE|WITH|MERGE|REPLACE|CALL)\b", sql_snippet, re.IGNORECASE):
+        return False
+
+    if any(pattern.search(sql_snippet) for pattern in PLACEHOLDER_STYLES.values()):
+        return False
+
+    # Python DB-API drivers bind %s / %(name)s
+    return not (language == "python" and re.search(r"%(?:s|\(\w+\)s)", sql_snippet))
+
+
+def _has_lock_queue_hazard(sql_snippet: str) -> bool:
+    """Check for a job-queue style lock: ORDER BY ... LIMIT ... FOR UPDATE without SKIP LOCKED."""
+    for statement in _split_statements(sql_snippet):
+        lock = re.search(
+            r"\bFOR\s+(?:NO\s+KEY\s+)?(?:UPDATE|SHARE)\b(?P<rest>.*)",
+            statement,
+            re.IGNORECASE | re.DOTALL
+        )
+        if not lock or re.search(r"\bSKIP\s+LOCKED\b", lock.group("rest"), re.IGNORECASE):
+            continue
+
+        head = statement[:lock.start()]
+        if re.search(r"\bORDER\s+BY\b", head, re.IGNORECASE) and re.search(r"\bLIMIT\b", head, re.IGNORECASE):
+            return True
+
+    return False
+
+
+def _extract_routine_calls(sql_snippet: str) -> list[dict[str, Any]]:
+    """Extract calls to stored functions and procedures.
+
+    CALL always names a procedure. Elsewhere, "name(" counts as a routine
+    call when the name is schema-qualified or isn't a built-in function,
+    type or keyword. DDL statements are skipped since their parentheses
+    hold column definitions and defaults.
+
+    Returns:
+        List of {"name", "args"} dicts in order of appearance
+    """
+    routines = []
+
+    for statement in _split_statements(sql_snippet):
+        if re.match(r"\s*(?:CREATE|ALTER|DROP)\b", statement, re.IGNORECASE):
+            continue
+
+        for match in re.finditer(r"(\b\w+\s+)?\b([A-Za-z_][\w.]*)\s*\(", statement):
+            previous = (match.group(1) or "").strip().lower()
+            name = match.group(2)
+            bare = name.rsplit(".", 1)[-1].lower()
+
+            if previous != "call":
+                if previous in TABLE_LIST_KEYWORDS:
+                    continue
+                if "." not in name and bare in BUILTIN_SQL_FUNCTIONS:
+                    continue
+
+            rest = statement[match.end():]
+            args = _split_top_level(rest[:_closing_paren(rest)], ",")
+            routines.append({"name": name, "args": args})
+
+    return routines
+
+
+def _extract_upsert(sql_snippet: str) -> dict[str, Any] | None:
+    """Parse INSERT ... ON CONFLICT and ON DUPLICATE KEY UPDATE clauses.
+
+    Args:
+        sql_snippet: SQL code snippet (comments removed)
+
+    Returns:
+        Dict with conflict_target columns (or constraint), action
+        ("update"/"nothing") and update_columns, or None for plain statements
+    """
+    if not re.search(r"\bINSERT\b", sql_snippet, re.IGNORECASE):
+        return None
+
+    conflict = re.search(
+        r"\bON\s+CONFLICT\s*(?:\(([^)]*)\)|ON\s+CONSTRAINT\s+([\w\"]+))?\s*"
+        r"DO\s+(UPDATE|NOTHING)\b",
+        sql_snippet,
+        re.IGNORECASE
+    )
+    if conflict:
+        target_columns, constraint, action = conflict.groups()
+        update_columns = []
+        if action.upper() == "UPDATE":
+            set_clause = re.search(
+                r"\bSET\b(.*?)(?:\bWHERE\b|\bRETURNING\b|$)",
+                sql_snippet[conflict.end():],
+                re.IGNORECASE | re.DOTALL
+            )
+            if set_clause:
+                update_columns = _assigned_columns(set_clause.group(1))
+
+        return {
+            "conflict_target": [
+                col.strip().strip('"') for col in (target_columns or "").split(",") if col.strip()
+            ],
+            "conflict_constraint": constraint.strip('"') if constraint else None,
+            "action": action.lower(),
+            "update_columns": update_columns,
+        }
+
+    duplicate = re.search(
+        r"\bON\s+DUPLICATE\s+KEY\s+UPDATE\b(.*)$", sql_snippet, re.IGNORECASE | re.DOTALL
+    )
+    if duplicate:
+        return {
+            "conflict_target": [],  # implied by the table's unique keys
+            "conflict_constraint": None,
+            "action": "update",
+            "update_columns": _assigned_columns(duplicate.group(1)),
+        }
+
+    return None
+
+
+def _assigned_columns(set_clause: str) -> list[str]:
+    """Return the column names assigned in a SET-style "col = expr, ..." list."""
+    return re.findall(r"(?:^|,)\s*[\"`]?(\w+)[\"`]?\s*=", set_clause.strip())
+
+
+def _split_statements(sql: str) -> list[str]:
+    """Split SQL on semicolons outside quoted strings and dollar-quoted bodies.
+
+    Args:
+        sql: SQL text with comments removed
+
+    Returns:
+        Non-empty statements
+    """
+    statements = []
+    current = []
+    i = 0
+    length = len(sql)
+
+    while i < length:
+        char = sql[i]
+
+        if char in ("'", '"'):
+            end = sql.find(char, i + 1)
+            end = length - 1 if end == -1 else end
+            current.append(sql[i:end + 1])
+            i = end + 1
+            continue
+
+        dollar = re.match(r"\$\w*\$", sql[i:])
+        if dollar:
+            tag = dollar.group(0)
+            end = sql.find(tag, i + len(tag))
+            end = length if end == -1 else end + len(tag)
+            current.append(sql[i:end])
+            i = end
+            continue
+
+        if char == ";":
+            statements.append("".join(current))
+            current = []
+        else:
+            current.append(char)
+        i += 1
+
+    statements.append("".join(current))
+    return [stmt.strip() for stmt in statements if stmt.strip()]
+
+
+def _extract_tables(
+    sql_snippet: str,
+    table_patterns: dict[str, str] | None = None
+) -> tuple[list[str], bool]:
+    """Extract table names referenced by a SQL snippet.
+
+    Dynamic table expressions (built with Sprintf verbs or template
+    placeholders) are dropped unless one of the table patterns maps them
+    to a canonical table.
+
+    Args:
+        sql_snippet: SQL code snippet
+        table_patterns: Optional regex -> canonical table mapping
+
+    Returns:
+        Tuple of (unique table names in order of appearance, whether an
+        unresolved dynamic table expression was seen)
+    """
+    tables = []
+    has_dynamic = False
+
+    refs = []  # (position, name)
+    for match in TABLE_REF_PATTERN.finditer(sql_snippet):
+        keyword = match.group(1).upper()
+
+        # FROM/JOIN directly followed by "(" is a function or subquery, not a table
+        if keyword in ("FROM", "JOIN") and sql_snippet[match.end():].lstrip().startswith("("):
+            continue
+
+        if keyword == "FROM":
+            refs.extend(
+                (match.start(2), name)
+                for name, _ in _parse_from_list(sql_snippet[match.start(2):])
+            )
+        else:
+            refs.append((match.start(2), match.group(2)))
+
+    for match in DELETE_USING_PATTERN.finditer(sql_snippet):
+        refs.extend(
+            (match.end(), name)
+            for name, _ in _parse_from_list(sql_snippet[match.end():])
+        )
+
+    names = [name for _, name in sorted(refs, key=lambda ref: ref[0])]
+
+    for name in names:
+        name = name.strip('"').rstrip(".")
+
+        if not name or name.lower() in NON_TABLE_WORDS:
+            continue
+
+        canonical = _normalize_table(name, table_patterns)
+        if canonical is None:
+            if DYNAMIC_TABLE_PATTERN.search(name):
+                has_dynamic = True
+                continue
+            canonical = name
+
+        if canonical not in tables:
+            tables.append(canonical)
+
+    return tables, has_dynamic
+
+
+def _extract_write_tables(
+    sql_snippet: str,
+    table_patterns: dict[str, str] | None = None
+) -> list[str]:
+    """Extract the tables written by INSERT, UPDATE and DELETE statements.
+
+    Auxiliary tables (DELETE ... USING, UPDATE ... FROM) are only read and
+    are left out.
+    """
+    tables = []
+
+    for match in WRITE_TARGET_PATTERN.finditer(sql_snippet):
+        name = match.group(1).strip('"').rstrip(".")
+        if not name or name.lower() in NON_TABLE_WORDS:
+            continue
+
+        canonical = _normalize_table(name, table_patterns)
+        if canonical is None:
+            if DYNAMIC_TABLE_PATTERN.search(name):
+                continue
+            canonical = name
+
+        if canonical not in tables:
+            tables.append(canonical)
+
+    return tables
+
+
+def _has_multi_table_write(sql_snippet: str) -> bool:
+    """Check for a DELETE ... USING or an UPDATE with a top-level FROM."""
+    if DELETE_USING_PATTERN.search(sql_snippet):
+        return True
+
+    for update in re.finditer(r"\bUPDATE\b.*?\bSET\b", sql_snippet, re.IGNORECASE | re.DOTALL):
+        if re.search(r"\b(?:FOR|DO|KEY|ON)\s+$", sql_snippet[:update.start()], re.IGNORECASE):
+            continue
+
+        depth = 0
+        tokens = re.finditer(r"\(|\)|;|\b(?:FROM|WHERE|RETURNING)\b", sql_snippet[update.end():], re.IGNORECASE)
+        for token in tokens:
+            text = token.group(0).upper()
+            if text == "(":
+                depth += 1
+            elif text == ")":
+                depth -= 1
+            elif depth == 0:
+                if text == "FROM":
+                    return True
+                break
+
+    return False
+
+
+def _extract_lock_tables(
+    sql_text: str,
+    tables: list[str],
+    table_patterns: dict[str, str] | None = None
+) -> list[str]:
+    """Return the tables a FOR UPDATE/FOR SHARE query locks rows in.
+
+    Without an OF list every table read is locked, in the order the join
+    reads them. An OF list naming tables or aliases narrows the locks;
+    names that can't be resolved keep all tables.
+    """
+    lock = ROW_LOCK_PATTERN.search(sql_text)
+    if not lock:
+        return []
+    if not lock.group(1):
+        return list(tables)
+
+    aliases = {}
+    for match in TABLE_ALIAS_PATTERN.finditer(sql_text):
+        table = _normalize_table(match.group(1).strip('"'), table_patterns) or match.group(1).strip('"')
+        aliases[table.split(".")[-1].lower()] = table
+        if match.group(2):
+            aliases[match.group(2).lower()] = table
+
+    locked = []
+    for name in lock.group(1).split(","):
+        table = aliases.get(name.strip().strip('"').split(".")[-1].lower())
+        if table is None:
+            return list(tables)
+        if table in tables and table not in locked:
+            locked.append(table)
+
+    return [table for table in tables if table in locked]
+
+
+def _parse_from_list(from_text: str) -> list[tuple[str, str]]:
+    """Parse a FROM list ("a x, b AS y, ...") into (table, alias) pairs.
+
+    Args:
+        from_text: Text starting at the first table after FROM
+
+    Returns:
+        List of (table, alias) pairs; the alias defaults to the bare table name
+    """
+    entries = []
+    entry_pattern = re.compile(
+        r"\s*([\w.\"%{}$]+)(?:\s+(?:AS\s+)?(?!" + FROM_LIST_STOP_WORDS + r"\b)(\w+))?\s*(,)?",
+        re.IGNORECASE
+    )
+
+    pos = 0
+    while True:
+        match = entry_pattern.match(from_text, pos)
+        if not match or match.group(1).startswith("("):
+            break
+        table = match.group(1)
+        alias = match.group(2) or table.strip('"').rsplit(".", 1)[-1]
+        entries.append((table, alias))
+        if not match.group(3):
+            break
+        pos = match.end()
+
+    return entries
+
+
+def _normalize_table(name: str, table_patterns: dict[str, str] | None) -> str | None:
+    """Map a table expression to its canonical table via the first matching pattern."""
+    if not table_patterns:
+        return None
+
+    for pattern, canonical in table_patterns.items():
+        if re.fullmatch(pattern, name, re.IGNORECASE):
+            return canonical
+
+    return None
+
+
+def _determine_tags(sql_snippet: str, call_type: str, framework: str) -> list[str]:
+    """Determine tags for a DB call.
+
+    Args:
+        sql_snippet: SQL code snippet
+        call_type: Type of call
+        framework: Framework name
+
+    Returns:
+        List of tags
+    """
+    tags = ["database"]
+
+    # Framework tag
+    if framework:
+        tags.append(f"db-{framework}")
+
+    # SQL operation tags; keywords must be whole words so columns such as
+    # created_at or user_id don't read as CREATE or USER
+    sql_upper = sql_snippet.upper()
+
+    def has(*keywords: str) -> bool:
+        return any(re.search(rf"\b{kw}\b", sql_upper) for kw in keywords)
+
+    # FOR UPDATE is a row lock, not a write
+    writes_upper = re.sub(r"\bFOR\s+(?:NO\s+KEY\s+)?UPDATE\b", " ", sql_upper)
+
+    if has("SELECT", "FETCH"):
+        tags.append("dml")
+        tags.append("select")
+
+    if any(re.search(rf"\b{kw}\b", writes_upper) for kw in ("INSERT", "UPDATE", "DELETE")):
+        tags.append("dml")
+        if re.search(r"\bINSERT\b", writes_upper):
+            tags.append("insert")
+        if re.search(r"\bUPDATE\b", writes_upper):
+            tags.append("update")
+        if re.search(r"\bDELETE\b", writes_upper):
+            tags.append("delete")
+
+    if has("CREATE", "ALTER", "DROP"):
+        tags.append("ddl")
+        tags.append("migrations")
+
+    if has("BEGIN", "COMMIT", "ROLLBACK", "TRANSACTION"):
+        tags.append("transactions")
+
+    if has("LOCK", "FOR UPDATE", "FOR SHARE"):
+        tags.append("locks")
+
+    if has("GRANT", "REVOKE", "ROLE", "USER"):
+        tags.append("auth-db")
+
+    # Migration-specific
+    if call_type == "migration":
+        tags.append("migrations")
+
+    return list(set(tags))  # Remove duplicates
+
+
+def scan_repository_for_db_calls(
+    repo_root: Path,
+    file_list: list[dict[str, Any]],
+    table_patterns: dict[str, str] | None = None,
+    default_schemas: list[str] | None = None,
+    allowed_schemas: list[str] | None = None,
+    allowed_tables: list[str] | None = None,
+    audit_table_pattern: str | None = None,
+    on_file: Callable[[str, list[DBCall]], None] | None = None,
+    dialect: str | None = None,
+    query_wrappers: dict[str, int] | None = None,
+    optional_checks: list[str] | None = None
+) -> list[DBCall]:
+    """Scan entire repository for database calls.
+
+    Args:
+        repo_root: Repository root path
+        file_list: List of files with language info
+        table_patterns: Optional regex -> canonical table mapping (see discover_db_calls)
+        default_schemas: Optional schemas to require on table references (see discover_db_calls)
+        allowed_schemas: Optional schema allowlist (see discover_db_calls)
+        allowed_tables: Optional table allowlist (see discover_db_calls)
+        audit_table_pattern: Optional audit table regex (see discover_db_calls)
+        on_file: Optional callback invoked with (path, calls) as each scanned
+            file finishes. Files are scanned one at a time, so calls are
+            serialized and made from the caller's thread. Lock order
+            conflict risks span files and are added after the last callback.
+        dialect: Optional SQL dialect for every file (see discover_db_calls)
+        query_wrappers: Optional query wrapper registrations (see discover_db_calls)
+        optional_checks: Optional off-by-default checks to run (see discover_db_calls)
+
+    Returns:
+        List of all discovered DB calls
+    """
+    _validate_optional_checks(optional_checks)
+    all_calls = []
+
+    for file_info in file_list:
+        file_path = repo_root / file_info["path"]
+        language = file_info["language"]
+
+        # Only scan supported languages
+        if language not in ("javascript", "typescript", "python", "go", "java", "sql"):
+            continue
+
+        try:
+            content = file_path.read_text(encoding="utf-8", errors="ignore")
+            calls = discover_db_calls(
+                str(file_path), content, language, table_patterns,
+                default_schemas, allowed_schemas, allowed_tables, audit_table_pattern,
+                dialect, query_wrappers, optional_checks
+            )
+        except Exception:
+            # Skip files that can't be read
+            continue
+
+        all_calls.extend(calls)
+        if on_file:
+            on_file(file_info["path"], calls)
+
+    # Lock ordering and column checks against migrations compare files, so
+    # they run once everything is scanned
+    find_lock_order_conflicts(all_calls)
+    find_dropped_column_references(all_calls)
+    find_unknown_column_writes(all_calls)
+    find_returning_unset_columns(all_calls)
+    find_type_mismatch_predicates(all_calls)
+
+    return all_calls
+
+
+def find_lock_order_conflicts(calls: list[DBCall]) -> list[dict[str, Any]]:
+    """Find transactions that lock the same tables in opposite orders.
+
+    A transaction is the set of calls in one function where at least one
+    call runs on a transaction handle or a FOR UPDATE/SHARE query joins
+    several tables. Its lock order is the first write (or FOR UPDATE/SHARE
+    read, in join order) of each table. Two transactions taking the
+    same pair of tables in opposite order can deadlock each other; the
+    first call of each gets a risk naming the other.
+
+    Args:
+        calls: DB calls from across the codebase
+
+    Returns:
+        List of {"tables", "first", "second"} conflicts, where first and
+        second are {"file", "function", "line", "order"} dicts
+    """
+    by_function: dict[tuple[str, str], list[DBCall]] = {}
+    for call in calls:
+        if call.function:
+            by_function.setdefault((call.file_path, call.function), []).append(call)
+
+    transactions = []
+    for (file_path, function), members in by_function.items():
+        # A joined FOR UPDATE locks several tables even outside a transaction
+        if not any(c.call_type == "transaction" or "transactions" in c.tags or len(c.lock_tables) > 1
+                   for c in members):
+            continue
+
+        members.sort(key=lambda c: c.start_line)
+        order = []
+        for call in members:
+            locked = call.lock_tables or (call.tables if "locks" in call.tags else call.write_tables)
+            order.extend(table for table in locked if table not in order)
+        if len(order) > 1:
+            transactions.append({
+                "file": file_path,
+                "function": function,
+                "line": members[0].start_line,
+                "order": order,
+                "call": members[0],
+            })
+
+    conflicts = []
+    for i, first in enumerate(transactions):
+        for second in transactions[i + 1:]:
+            for a_index, a in enumerate(first["order"]):
+                for b in first["order"][a_index + 1:]:
+                    if a in second["order"] and b in second["order"] and \
+                            second["order"].index(b) < second["order"].index(a):
+                        conflicts.append({
+                            "tables": [a, b],
+                            "first": {k: v for k, v in first.items() if k != "call"},
+                            "second": {k: v for k, v in second.items() if k != "call"},
+                        })
+                        for this, other in ((first, second), (second, first)):
+                            this["call"].risks.append(
+                                f"Locks {' then '.join(this['order'])} but {other['function']} "
+                                f"({other['file']}:{other['line']}) takes {a} and {b} in the "
+                                "opposite order - possible deadlock"
+                            )
+
+    return conflicts
+
+
+def find_dropped_column_references(calls: list[DBCall]) -> list[dict[str, Any]]:
+    """Find application queries using columns that an up migration drops.
+
+    Columns dropped with ALTER TABLE ... DROP COLUMN in up migrations are
+    matched against queries reading or writing the same table. Column
+    names qualified with another table's alias don't count. Each
+    referencing call gets a risk naming the migration.
+
+    Args:
+        calls: DB calls from across the codebase, migrations included
+
+    Returns:
+        List of {"table", "column", "migration", "reference"} dicts, where
+        migration is {"file", "line"} and reference is
+        {"file", "function", "line"}
+    """
+    dropped = []  # (table, column, migration call)
+    for call in calls:
+        if call.call_type != "migration" or "migration-down" in call.tags:
+            continue
+        alter = ALTER_TABLE_PATTERN.search(_strip_sql_comments(call.sql_snippet))
+        if alter:
+            table = alter.group(1).strip('"')
+            dropped.extend(
+                (table, column.lower(), call)
+                for column in DROP_COLUMN_PATTERN.findall(alter.group(2))
+            )
+
+    references = []
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+
+        # String literals can contain the column name as plain text
+        sql_text = re.sub(r"'[^']*'", "''", _strip_sql_comments(call.sql_snippet))
+        for table, column, migration in dropped:
+            used = [t for t in call.tables if _same_table(t, table)]
+            if not used:
+                continue
+
+            aliases = {
+                (match.group(2) or match.group(1).split(".")[-1]).strip('"').lower()
+                for match in TABLE_ALIAS_PATTERN.finditer(sql_text)
+                if _same_table(match.group(1).strip('"'), table)
+            }
+            for match in re.finditer(rf"(?:(\w+)\.)?\b{re.escape(column)}\b", sql_text, re.IGNORECASE):
+                if match.group(1) and match.group(1).lower() not in aliases:
+                    continue
+
+                references.append({
+                    "table": used[0],
+                    "column": column,
+                    "migration": {"file": migration.file_path, "line": migration.start_line},
+                    "reference": {"file": call.file_path, "function": call.function, "line": call.start_line},
+                })
+                call.risks.append(
+                    f"References {used[0]}.{column}, which migration "
+                    f"{migration.file_path}:{migration.start_line} drops - the query fails once it is applied"
+                )
+                break
+
+    return references
+
+
+def find_unknown_column_writes(
+    calls: list[DBCall],
+    known_columns: dict[str, list[str]] | None = None
+) -> list[dict[str, Any]]:
+    """Find INSERT column lists and UPDATE SET clauses naming unknown columns.
+
+    A table's columns come from known_columns (e.g. an introspected
+    schema) and from CREATE TABLE and ALTER TABLE ... ADD COLUMN
+    statements among the calls. Tables without known columns aren't
+    checked. Each offending call gets a risk.
+
+    Args:
+        calls: DB calls from across the codebase, migrations included
+        known_columns: Optional table -> column names mapping
+
+    Returns:
+        List of {"table", "column", "file", "function", "line"} dicts
+    """
+    columns: dict[str, set[str]] = {
+        table: {column.lower() for column in names} for table, names in (known_columns or {}).items()
+    }
+    for table, declared in _declared_columns(calls).items():
+        columns.setdefault(table, set()).update(declared)
+
+    unknown = []
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            written = []  # (table as written, column)
+            insert = re.search(r"\bINSERT\s+INTO\s+([\w.\"`]+)\s*\(", statement, re.IGNORECASE)
+            if insert:
+                rest = statement[insert.end():]
+                written.extend(
+                    (insert.group(1), item.strip().strip('"`'))
+                    for item in _split_top_level(rest[:_closing_paren(rest)], ",")
+                )
+            update = re.search(
+                r"\bUPDATE\s+(?:ONLY\s+)?([\w.\"`]+)(?:\s+(?:AS\s+)?\w+)?\s+SET\b(.*?)(?:\bWHERE\b|\bFROM\b|\bRETURNING\b|$)",
+                statement, re.IGNORECASE | re.DOTALL
+            )
+            if update:
+                written.extend((update.group(1), column) for column in _assigned_columns(update.group(2)))
+
+            for table, column in written:
+                known = next((cols for name, cols in columns.items() if _same_table(name, table.strip('"`'))), None)
+                if known is None or not re.fullmatch(r"\w+", column) or column.lower() in known:
+                    continue
+
+                table = next((t for t in call.tables if _same_table(t, table.strip('"`'))), table)
+                unknown.append({
+                    "table": table,
+                    "column": column,
+                    "file": call.file_path,
+                    "function": call.function,
+                    "line": call.start_line,
+                })
+                call.risks.append(
+                    f"Writes {table}.{column}, which is not a known column of {table} - "
+                    "the statement fails against this schema"
+                )
+
+    return unknown
+
+
+def find_returning_unset_columns(calls: list[DBCall]) -> list[dict[str, Any]]:
+    """Find INSERT ... RETURNING columns the INSERT doesn't set and that have no default.
+
+    Column definitions come from CREATE TABLE and ALTER TABLE ... ADD
+    COLUMN statements among the calls. DEFAULT, SERIAL, IDENTITY and
+    GENERATED columns are filled in by the database, so only columns
+    without any of them are reported. Each offending call gets a risk.
+
+    Args:
+        calls: DB calls from across the codebase, migrations included
+
+    Returns:
+        List of {"table", "column", "file", "function", "line"} dicts
+    """
+    declared = _declared_columns(calls)
+    unset = []
+
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            insert = re.search(r"\bINSERT\s+INTO\s+([\w.\"`]+)\s*\(", statement, re.IGNORECASE)
+            returning = re.search(r"\bRETURNING\s+(.*)$", statement, re.IGNORECASE | re.DOTALL)
+            if not insert or not returning:
+                continue
+            table = insert.group(1).strip('"`')
+            definitions = next((defs for name, defs in declared.items() if _same_table(name, table)), None)
+            if definitions is None:
+                continue
+
+            rest = statement[insert.end():]
+            inserted = {
+                item.strip().strip('"`').lower() for item in _split_top_level(rest[:_closing_paren(rest)], ",")
+            }
+            for item in _split_top_level(returning.group(1), ","):
+                column = item.strip().strip('"`')
+                definition = definitions.get(column.lower())
+                if definition is None or column.lower() in inserted or DATABASE_FILLED_PATTERN.search(definition):
+                    continue
+
+                table = next((t for t in call.tables if _same_table(t, table)), table)
+                unset.append({
+                    "table": table,
+                    "column": column,
+                    "file": call.file_path,
+                    "function": call.function,
+                    "line": call.start_line,
+                })
+                call.risks.append(
+                    f"RETURNING {table}.{column}, which the INSERT doesn't set and which has no "
+                    "default - it comes back NULL unless a trigger sets it"
+                )
+
+    return unset
+
+
+def find_type_mismatch_predicates(
+    calls: list[DBCall],
+    known_types: dict[str, dict[str, str]] | None = None
+) -> list[dict[str, Any]]:
+    """Find WHERE predicates comparing a column to a literal of the other type kind.
+
+    A numeric column compared to a quoted string, or a text column
+    compared to a bare number, makes the database cast one side and can
+    keep an index from being used. Column types come from known_types
+    (table -> column -> type, e.g. an introspected schema) and from
+    CREATE TABLE and ALTER TABLE ... ADD COLUMN statements among the
+    calls. Columns of unknown type aren't checked. Each offending call
+    gets a risk.
+
+    Args:
+        calls: DB calls from across the codebase, migrations included
+        known_types: Optional table -> column -> type mapping
+
+    Returns:
+        List of {"table", "column", "type", "literal", "file", "function", "line"} dicts
+    """
+    types: dict[str, dict[str, str]] = {
+        table: {column.lower(): column_type for column, column_type in columns.items()}
+        for table, columns in (known_types or {}).items()
+    }
+    for table, declared in _declared_columns(calls).items():
+        known = types.setdefault(table, {})
+        for column, definition in declared.items():
+            known.setdefault(column, definition.split()[0] if definition else "")
+
+    mismatches = []
+    for call in calls:
+        if call.call_type == "migration":
+            continue
+
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            where = WHERE_CLAUSE_PATTERN.search(statement)
+            if not where:
+                continue
+
+            for predicate in LITERAL_PREDICATE_PATTERN.finditer(where.group(1)):
+                column, literal = predicate.group(1), predicate.group(2)
+                owners = [
+                    (table, types[name][column.lower()])
+                    for table in call.tables
+                    for name in types
+                    if _same_table(name, table) and column.lower() in types[name]
+                ]
+                if len(owners) != 1:
+                    continue
+
+                table, column_type = owners[0]
+                # information_schema spells types out (character varying, double precision)
+                kind = re.sub(r"\(.*", "", column_type).split()[0].lower()
+                kind = DIALECT_TYPE_NAMES.get(call.dialect, {}).get(kind, kind)
+                if literal.startswith("'") and kind in NUMERIC_COLUMN_TYPES:
+                    fix = "compare to a number or a bind parameter"
+                elif not literal.startswith("'") and kind in TEXT_COLUMN_TYPES:
+                    fix = "quote the literal or use a bind parameter"
+                else:
+                    continue
+
+                mismatches.append({
+                    "table": table,
+                    "column": column,
+                    "type": column_type,
+                    "literal": literal,
+                    "file": call.file_path,
+                    "function": call.function,
+                    "line": call.start_line,
+                })
+                call.risks.append(
+                    f"{table}.{column} is {column_type} but is compared to `{literal}` - the implicit "
+                    f"cast can keep an index from being used; {fix}"
+                )
+
+    return mismatches
+
+
+def _declared_columns(calls: list[DBCall]) -> dict[str, dict[str, str]]:
+    """Column definitions declared by the up-migration DDL among the calls.
+
+    Maps table -> lowercased column -> the rest of its definition (type
+    and constraints), from CREATE TABLE and ALTER TABLE ... ADD COLUMN.
+    """
+    declared: dict[str, dict[str, str]] = {}
+
+    for call in calls:
+        if call.call_type != "migration" or "migration-down" in call.tags:
+            continue
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            ddl = CREATE_TABLE_PATTERN.match(statement)
+            alter = ALTER_TABLE_PATTERN.search(statement)
+            if ddl:
+                body = statement[ddl.end():]
+                columns = declared.setdefault(ddl.group(1).strip('"'), {})
+                for column_def in _split_top_level(body[:_closing_paren(body)], ","):
+                    column = re.match(r"\s*[\"`]?(\w+)[\"`]?\s+(\w.*)", column_def, re.DOTALL)
+                    if column and column.group(1).upper() not in TABLE_CONSTRAINT_WORDS:
+                        columns[column.group(1).lower()] = column.group(2).strip()
+            elif alter:
+                for action in _split_top_level(alter.group(2), ","):
+                    added = re.match(
+                        r"\s*ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?"
+                        r"(?!(?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK)\b)[\"`]?(\w+)[\"`]?(.*)",
+                        action, re.IGNORECASE | re.DOTALL
+                    )
+                    if added:
+                        columns = declared.setdefault(alter.group(1).strip('"'), {})
+                        columns[added.group(1).lower()] = added.group(2).strip()
+
+    return declared
+
+
+def _same_table(a: str, b: str) -> bool:
+    """Whether two table names match, ignoring a schema missing on one side."""
+    a, b = a.lower(), b.lower()
+    if "." in a and "." in b:
+        return a == b
+    return a.split(".")[-1] == b.split(".")[-1]
+
+
+def redact_sql(sql: str) -> str:
+    """Mask literal values in query text for reports shared outside the team.
+
+    String and number literals become ***, as do passwords in connection
+    strings (password=... and user:password@ URLs). Keywords, identifiers
+    and bind placeholders ($1, ?, :name) are kept so the query shape stays
+    readable.
+
+    Args:
+        sql: Query text
+
+    Returns:
+        Redacted query text
+    """
+    redacted = re.sub(r"'(?:[^']|'')*'", "'***'", _redact_passwords(sql))
+    return re.sub(r"(?<![\w$.:*])\d+(?:\.\d+)?(?![\w.])", "***", redacted)
+
+
+def redact_risk(risk: str) -> str:
+    """Mask the query text quoted in a risk description, leaving its prose alone.
+
+    Risks wrap the SQL they quote in backticks; only those spans go
+    through redact_sql, so counts, line numbers and quoted identifiers in
+    the prose survive. Connection-string passwords are masked anywhere.
+
+    Args:
+        risk: Risk description
+
+    Returns:
+        Redacted risk description
+    """
+    redacted = re.sub(r"`([^`]*)`", lambda quoted: f"`{redact_sql(quoted.group(1))}`", risk)
+    return _redact_passwords(redacted)
+
+
+def _redact_passwords(text: str) -> str:
+    """Mask passwords in connection strings (password=... and user:password@ URLs)."""
+    redacted = re.sub(r"(://[^:/@\s]+:)[^@\s]+@", r"\1***@", text)
+    return re.sub(r"\b(password|pwd)\s*=\s*(?:'[^']*'|[^\s;]+)", r"\1=***", redacted, flags=re.IGNORECASE)
+
+
+def build_table_index(calls: list[DBCall]) -> dict[str, list[dict[str, Any]]]:
+    """Map each referenced table to the locations that use it.
+
+    Args:
+        calls: List of DB calls
+
+    Returns:
+        Table -> list of {"file", "line", "function"} in discovery order
+    """
+    index: dict[str, list[dict[str, Any]]] = {}
+
+    for call in calls:
+        for table in call.tables:
+            index.setdefault(table, []).append({
+                "file": call.file_path,
+                "line": call.start_line,
+                "function": call.function,
+            })
+
+    return index
+
+
+def find_table_usages(calls: list[DBCall], table: str) -> list[dict[str, Any]]:
+    """Find where a table is used.
+
+    A bare name also matches schema-qualified references (orders matches
+    test_schema.orders).
+
+    Args:
+        calls: List of DB calls
+        table: Table name, optionally schema-qualified
+
+    Returns:
+        List of {"file", "line", "function"} locations
+    """
+    return lookup_table_usages(build_table_index(calls), table)
+
+
+def lookup_table_usages(index: dict[str, list[dict[str, Any]]], table: str) -> list[dict[str, Any]]:
+    """Find where a table is used in a table index (see build_table_index).
+
+    A bare name also matches schema-qualified references (orders matches
+    test_schema.orders).
+
+    Args:
+        index: Table -> locations, as built by build_table_index
+        table: Table name, optionally schema-qualified
+
+    Returns:
+        List of {"file", "line", "function"} locations
+    """
+    wanted = table.lower()
+    usages = []
+
+    for name, locations in index.items():
+        name = name.lower()
+        if name == wanted or ("." not in wanted and name.rsplit(".", 1)[-1] == wanted):
+            usages.extend(locations)
+
+    return usages
+
+
+def build_dependency_manifest(calls: list[DBCall]) -> dict[str, Any]:
+    """Build a manifest of the database objects the code depends on.
+
+    Columns are collected from CREATE TABLE statements and from the
+    select lists, INSERT/SET/RETURNING lists and simple predicates of
+    single-table queries. SERIAL columns imply a <table>_<column>_seq
+    sequence, as Postgres names them.
+
+    The fingerprint is a SHA-256 over the manifest plus the declared
+    column types and constraints, with every list sorted and definitions
+    whitespace-normalized, so it only changes when the database surface
+    does, not when queries are reordered or reformatted.
+
+    Args:
+        calls: List of DB calls
+
+    Returns:
+        Dict with schemas, tables (name -> columns), routines, sequences,
+        foreign_keys and fingerprint
+    """
+    tables: dict[str, list[str]] = {}
+    definitions: dict[str, set[str]] = {}  # table -> normalized column and constraint definitions
+    routines = set()
+    sequences = set()
+    foreign_keys = []
+
+    def add_columns(table: str, columns: list[str]) -> None:
+        known = tables.setdefault(table, [])
+        for column in columns:
+            if column not in known:
+                known.append(column)
+
+    for call in calls:
+        for table in call.tables:
+            tables.setdefault(table, [])
+        routines.update(routine["name"] for routine in call.routines)
+
+        for statement in _split_statements(_strip_sql_comments(call.sql_snippet)):
+            ddl = CREATE_TABLE_PATTERN.match(statement)
+            if ddl:
+                table = ddl.group(1).strip('"')
+                body = statement[ddl.end():]
+                for column_def in _split_top_level(body[:_closing_paren(body)], ","):
+                    definitions.setdefault(table, set()).add(" ".join(column_def.split()).upper())
+                    column = re.match(r"[\"`]?(\w+)[\"`]?\s+(\w+)", column_def)
+                    if not column or column.group(1).upper() in TABLE_CONSTRAINT_WORDS:
+                        continue
+                    add_columns(table, [column.group(1)])
+                    if column.group(2).upper() in ("SERIAL", "BIGSERIAL", "SMALLSERIAL"):
+                        sequences.add(f"{table}_{column.group(1)}_seq")
+                    ref = re.search(r"\bREFERENCES\s+([\w.\"]+)\s*\(\s*(\w+)", column_def, re.IGNORECASE)
+                    if ref:
+                        target = ref.group(1).strip('"')
+                        foreign_keys.append({
+                            "table": table,
+                            "column": column.group(1),
+                            "references": f"{target}({ref.group(2)})",
+                        })
+            elif len(call.tables) == 1:
+                add_columns(call.tables[0], _referenced_columns(statement))
+
+    schemas = sorted({table.rsplit(".", 1)[0] for table in tables if "." in table})
+
+    surface = {
+        "tables": {name: sorted(columns) for name, columns in tables.items()},
+        "definitions": {name: sorted(defs) for name, defs in definitions.items()},
+        "routines": sorted(routines),
+        "sequences": sorted(sequences),
+        "foreign_keys": sorted(json.dumps(fk, sort_keys=True) for fk in foreign_keys),
+    }
+    fingerprint = hashlib.sha256(json.dumps(surface, sort_keys=True).encode()).hexdigest()
+
+    return {
+        "schemas": schemas,
+        "tables": {name: tables[name] for name in sorted(tables)},
+        "routines": sorted(routines),
+        "sequences": sorted(sequences),
+        "foreign_keys": foreign_keys,
+        "fingerprint": fingerprint,
+    }
+
+
+def _referenced_columns(statement: str) -> list[str]:
+    """Column names a single-table statement reads or writes (simple identifiers only)."""
+    columns = []
+    lists = []
+
+    select = re.search(r"\bSELECT\s+(.*?)\s+FROM\b", statement, re.IGNORECASE | re.DOTALL)
+    if select:
+        lists.append(select.group(1))
+    insert = re.search(r"\bINSERT\s+INTO\s+[\w.\"]+\s*\(", statement, re.IGNORECASE)
+    if insert:
+        rest = statement[insert.end():]
+        lists.append(rest[:_closing_paren(rest)])
+    returning = re.search(r"\bRETURNING\s+(.*)$", statement, re.IGNORECASE | re.DOTALL)
+    if returning:
+        lists.append(returning.group(1))
+    order_by = re.search(r"\bORDER\s+BY\s+(.*?)(?:\bLIMIT\b|\bOFFSET\b|\bFOR\b|$)", statement, re.IGNORECASE | re.DOTALL)
+    if order_by:
+        lists.append(re.sub(r"\s+(?:ASC|DESC)\b", "", order_by.group(1), flags=re.IGNORECASE))
+
+    for item_list in lists:
+        for item in _split_top_level(item_list, ","):
+            column = re.fullmatch(r"(?:\w+\.)?[\"`]?(\w+)[\"`]?", item)
+            if column:
+                columns.append(column.group(1))
+
+    set_clause = re.search(r"\bSET\b(.*?)(?:\bWHERE\b|\bFROM\b|\bRETURNING\b|$)", statement, re.IGNORECASE | re.DOTALL)
+    if set_clause and not re.match(r"\s*(?:LOCAL|SESSION)\b", set_clause.group(1), re.IGNORECASE):
+        columns.extend(_assigned_columns(set_clause.group(1)))
+
+    where = re.search(r"\bWHERE\b(.*)", statement, re.IGNORECASE | re.DOTALL)
+    if where:
+        columns.extend(re.findall(
+            r"(?:\b\w+\.)?\b([A-Za-z_]\w*)\s*(?:=|<>|!=|<=|>=|<|>|\bI?LIKE\b|\bIN\b|\bIS\b)",
+            where.group(1),
+            re.IGNORECASE
+        ))
+
+    unique = []
+    for column in columns:
+        if column not in unique and column.lower() not in MANIFEST_NON_COLUMNS:
+            unique.append(column)
+    return unique
+
+
+def summarize_db_calls(calls: list[DBCall]) -> dict[str, Any]:
+    """Summarize discovered database calls.
+
+    Findings (risk-only call sites without SQL, such as a bare Begin())
+    are counted separately and left out of every other statistic.
+
+    Args:
+        calls: List of DB calls
+
+    Returns:
+        Summary statistics
+    """
+    findings = sum(1 for call in calls if call.kind == "finding")
+    calls = [call for call in calls if call.kind != "finding"]
+    total = len(calls)
+
+    # Count by language
+    by_language = {}
+    for call in calls:
+        by_language[call.language] = by_language.get(call.language, 0) + 1
+
+    # Count by framework
+    by_framework = {}
+    for call in calls:
+        by_framework[call.framework] = by_framework.get(call.framework, 0) + 1
+
+    # Count by call type
+    by_type = {}
+    for call in calls:
+        by_type[call.call_type] = by_type.get(call.call_type, 0) + 1
+
+    # Collect unique tags
+    all_tags = set()
+    for call in calls:
+        all_tags.update(call.tags)
+
+    # Count by SQL operation
+    by_operation = {}
+    for call in calls:
+        for operation in ("select", "insert", "update", "delete", "ddl"):
+            if operation in call.tags:
+                by_operation[operation] = by_operation.get(operation, 0) + 1
+
+    # Collect referenced tables and stored routines
+    all_tables = set()
+    all_routines = set()
+    for call in calls:
+        all_tables.update(call.tables)
+        all_routines.update(routine["name"] for routine in call.routines)
+
+    return {
+        "total_calls": total,
+        "findings": findings,
+        "by_language": by_language,
+        "by_framework": by_framework,
+        "by_type": by_type,
+        "by_operation": by_operation,
+        "distinct_tables": sorted(all_tables),
+        "table_index": build_table_index(calls),
+        "routine_calls": sorted(all_routines),
+        "transaction_calls": sum(
+            1 for c in calls if c.call_type == "transaction" or "transactions" in c.tags
+        ),
+        "locking_calls": sum(1 for c in calls if "locks" in c.tags),
+        "ddl_calls": by_operation.get("ddl", 0),
+        "unique_tags": sorted(list(all_tags)),
+        "sample_calls": [
+            {
+                "file": call.file_path,
+                "line": call.start_line,
+                "framework": call.framework,
+                "type": call.call_type,
+                "snippet": call.sql_snippet[:100]  # First 100 chars
+            }
             for call in calls[:10]  # Top 10 samples
         ]
     }
+
+
+# Off-by-default checks, enabled by name through optional_checks
+OPTIONAL_CHECKS: dict[str, Callable[[list[DBCall], str, str], None]] = {
+    "nondeterministic-predicate": _flag_nondeterministic_predicates,
+    "nullable-foreign-key": _flag_nullable_foreign_keys,
+    "strict-parameterization": _flag_dynamic_sql,
+    "suspicious-distinct": _flag_suspicious_distinct,
+    "untyped-scan": _flag_untyped_scans,
+    "weak-isolation": _flag_weak_isolation,
+}
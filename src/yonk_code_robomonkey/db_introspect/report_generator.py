@@ -8,13 +8,17 @@ and application database calls.
 import hashlib
 import json
 from dataclasses import dataclass, asdict
-from typing import Any
+from typing import Any, Callable
 import asyncpg
 from datetime import datetime
 
 from yonk_code_robomonkey.db_introspect.schema_extractor import extract_db_schema, DBSchema
 from yonk_code_robomonkey.db_introspect.routine_analyzer import analyze_routine
-from yonk_code_robomonkey.db_introspect.app_call_discoverer import discover_db_calls
+from yonk_code_robomonkey.db_introspect.app_call_discoverer import (
+    DBCall, build_table_index, discover_db_calls, find_dropped_column_references,
+    find_lock_order_conflicts, find_returning_unset_columns, find_type_mismatch_predicates,
+    find_unknown_column_writes, redact_risk, redact_sql
+)
 from yonk_code_robomonkey.db.schema_manager import resolve_repo_to_schema, schema_context
 
 
@@ -35,7 +39,17 @@ async def generate_db_architecture_report(
     regenerate: bool = False,
     schemas: list[str] | None = None,
     max_routines: int = 50,
-    max_app_calls: int = 100
+    max_app_calls: int = 100,
+    redact: bool = False,
+    table_patterns: dict[str, str] | None = None,
+    default_schemas: list[str] | None = None,
+    allowed_schemas: list[str] | None = None,
+    allowed_tables: list[str] | None = None,
+    audit_table_pattern: str | None = None,
+    dialect: str | None = None,
+    query_wrappers: dict[str, int] | None = None,
+    optional_checks: list[str] | None = None,
+    on_file: Callable[[str, list[DBCall]], None] | None = None
 ) -> DBReportResult:
     """
     Generate comprehensive database architecture report.
@@ -48,10 +62,49 @@ async def generate_db_architecture_report(
         schemas: List of schema names to analyze (None = all non-system schemas)
         max_routines: Maximum routines to include in top lists
         max_app_calls: Maximum app calls to include
+        redact: Mask literals and passwords in query text, and in the SQL
+            that risk descriptions quote, throughout the returned report and
+            its markdown (the cached copy is stored unredacted)
+        table_patterns: Regex -> canonical table mapping for sharded or
+            prefixed table names in app queries (see discover_db_calls)
+        default_schemas: Schemas app queries are expected to qualify tables
+            with; unqualified table references are flagged
+        allowed_schemas: Schemas app queries may touch; together with
+            allowed_tables, qualified references outside them are flagged
+        allowed_tables: Tables (bare or schema-qualified) app queries may
+            touch in addition to allowed_schemas
+        audit_table_pattern: Regex for audit/event/log tables whose plain
+            INSERTs are flagged as non-idempotent (see discover_db_calls)
+        dialect: SQL dialect for the dialect-specific app query checks
+            (postgres, mysql, sqlite or a registered one); detected per file
+            from its drivers when omitted
+        query_wrappers: Function name -> SQL argument index for project
+            helpers that execute queries (see discover_db_calls)
+        optional_checks: Names of off-by-default app query checks to run
+            (see OPTIONAL_CHECKS in app_call_discoverer)
+        on_file: Optional progress callback invoked with (path, calls) as
+            each file's app calls are discovered. Files are processed one
+            at a time, so calls are serialized. Cross-file risks (lock
+            order, migration column checks) are added after the last
+            callback, and cached reports make no calls.
 
     Returns:
         DBReportResult with cached flag, JSON, markdown, timestamp, and hash
     """
+    # App call discovery options; unset ones are left to the discoverer's defaults
+    discovery_options = {
+        key: value for key, value in {
+            'table_patterns': table_patterns,
+            'default_schemas': default_schemas,
+            'allowed_schemas': allowed_schemas,
+            'allowed_tables': allowed_tables,
+            'audit_table_pattern': audit_table_pattern,
+            'dialect': dialect,
+            'query_wrappers': query_wrappers,
+            'optional_checks': optional_checks,
+        }.items() if value is not None
+    }
+
     conn = await asyncpg.connect(dsn=database_url)
     try:
         # Resolve repo_id to schema
@@ -65,7 +118,9 @@ async def generate_db_architecture_report(
 
         async with schema_context(conn, schema_name):
             # Calculate content hash for caching
-            content_hash = await _calculate_content_hash(conn, repo_id, target_db_url, schemas or [])
+            content_hash = await _calculate_content_hash(
+                conn, repo_id, target_db_url, schemas or [], discovery_options
+            )
 
             # Check for cached report
             if not regenerate:
@@ -84,6 +139,8 @@ async def generate_db_architecture_report(
                 )
                 if cached:
                     report_json = json.loads(cached['content'])
+                    if redact:
+                        report_json = _redact_report(report_json)
                     report_text = _generate_markdown(report_json)
                     return DBReportResult(
                         cached=True,
@@ -95,7 +152,8 @@ async def generate_db_architecture_report(
 
             # Generate new report
             report_data = await _generate_report_data(
-                conn, repo_id, target_db_url, schemas, max_routines, max_app_calls
+                conn, repo_id, target_db_url, schemas, max_routines, max_app_calls,
+                discovery_options, on_file
             )
             report_data['metadata'] = {
                 'generated_at': datetime.utcnow().isoformat(),
@@ -108,6 +166,10 @@ async def generate_db_architecture_report(
             # Store report as document
             await _store_report(conn, repo_id, report_data, report_text, content_hash)
 
+            if redact:
+                report_data = _redact_report(report_data)
+                report_text = _generate_markdown(report_data)
+
         return DBReportResult(
             cached=False,
             report_json=report_data,
@@ -119,13 +181,36 @@ async def generate_db_architecture_report(
         await conn.close()
 
 
+def _redact_report(report: dict[str, Any]) -> dict[str, Any]:
+    """Return a copy of the report with query text and the SQL quoted in risks redacted.
+
+    Query text is fully redacted; risk descriptions only in the SQL they
+    quote, so their prose (counts, line numbers) stays readable.
+    """
+    redacted = json.loads(json.dumps(report, default=str))
+
+    for call in redacted.get('app_db_calls', {}).get('sample_calls', []):
+        if call.get('sql_snippet'):
+            call['sql_snippet'] = redact_sql(call['sql_snippet'])
+        call['risks'] = [redact_risk(risk) for risk in call.get('risks', [])]
+
+    for routine in redacted.get('stored_routines', {}).get('risky_routines', []):
+        routine['risks'] = [redact_risk(risk) for risk in routine.get('risks', [])]
+
+    for risk in redacted.get('risk_analysis', {}).get('risks', []):
+        risk['details'] = redact_risk(risk['details'])
+
+    return redacted
+
+
 async def _calculate_content_hash(
     conn: asyncpg.Connection,
     repo_id: str,
     target_db_url: str,
-    schemas: list[str]
+    schemas: list[str],
+    discovery_options: dict[str, Any] | None = None
 ) -> str:
-    """Calculate content hash for caching based on DB fingerprint."""
+    """Calculate content hash for caching based on DB fingerprint and discovery options."""
     # Get target DB version and object count as fingerprint
     target_conn = await asyncpg.connect(dsn=target_db_url)
     try:
@@ -162,7 +247,8 @@ async def _calculate_content_hash(
             repo_id
         )
 
-        fingerprint = f"{version}:{table_count}:{func_count}:{last_file_mtime}"
+        options = json.dumps(discovery_options or {}, sort_keys=True)
+        fingerprint = f"{version}:{table_count}:{func_count}:{last_file_mtime}:{options}"
         return hashlib.sha256(fingerprint.encode()).hexdigest()
     finally:
         await target_conn.close()
@@ -174,7 +260,9 @@ async def _generate_report_data(
     target_db_url: str,
     schemas: list[str] | None,
     max_routines: int,
-    max_app_calls: int
+    max_app_calls: int,
+    discovery_options: dict[str, Any] | None = None,
+    on_file: Callable[[str, list[DBCall]], None] | None = None
 ) -> dict[str, Any]:
     """Generate report data structure."""
     # Extract DB schema
@@ -197,7 +285,9 @@ async def _generate_report_data(
         })
 
     # Discover app DB calls
-    app_calls = await _discover_app_calls(conn, repo_id, max_app_calls)
+    app_calls, table_index = await _discover_app_calls(
+        conn, repo_id, max_app_calls, discovery_options, on_file, db_schema
+    )
 
     # Build report structure
     report = {
@@ -205,8 +295,8 @@ async def _generate_report_data(
         'schema_map': _build_schema_map(db_schema),
         'objects_inventory': _build_objects_inventory(db_schema),
         'stored_routines': _build_routines_summary(routine_analyses),
-        'risk_analysis': _build_risk_analysis(db_schema, routine_analyses),
-        'app_db_calls': _build_app_calls_summary(app_calls),
+        'risk_analysis': _build_risk_analysis(db_schema, routine_analyses, app_calls),
+        'app_db_calls': _build_app_calls_summary(app_calls, table_index),
         'migration_info': _build_migration_info(db_schema)
     }
 
@@ -350,7 +440,11 @@ def _build_routines_summary(analyses: list[dict[str, Any]]) -> dict[str, Any]:
     }
 
 
-def _build_risk_analysis(schema: DBSchema, analyses: list[dict[str, Any]]) -> dict[str, Any]:
+def _build_risk_analysis(
+    schema: DBSchema,
+    analyses: list[dict[str, Any]],
+    app_calls: list[dict[str, Any]] | None = None
+) -> dict[str, Any]:
     """Build risk and compatibility analysis."""
     risks = []
 
@@ -375,6 +469,16 @@ def _build_risk_analysis(schema: DBSchema, analyses: list[dict[str, Any]]) -> di
                     'details': risk
                 })
 
+    # Check for risky application queries
+    for call in app_calls or []:
+        for risk in call.get('risks', []):
+            risks.append({
+                'type': 'app_query_risk',
+                'severity': _app_risk_severity(risk),
+                'location': f"{call['file_path']}:{call['line']}",
+                'details': risk
+            })
+
     # Check for triggers (can impact performance)
     if len(schema.triggers) > 10:
         risks.append({
@@ -389,24 +493,49 @@ def _build_risk_analysis(schema: DBSchema, analyses: list[dict[str, Any]]) -> di
     }
 
 
+def _app_risk_severity(risk: str) -> str:
+    """Severity of an application query risk from its description."""
+    text = risk.lower()
+    if 'injection' in text or 'forbidden' in text or text.startswith('strict parameterization'):
+        return 'high'
+    if ('no bind parameters' in text or text.startswith('joined lock order')
+            or 'in a where predicate' in text or text.startswith('foreign key column')
+            or 'untyped interface{} values' in text
+            or text.startswith('select distinct over a join')):
+        return 'low'
+    return 'medium'
+
+
 async def _discover_app_calls(
     conn: asyncpg.Connection,
     repo_id: str,
-    max_calls: int
-) -> list[dict[str, Any]]:
-    """Discover application database calls from indexed files."""
+    max_calls: int,
+    discovery_options: dict[str, Any] | None = None,
+    on_file: Callable[[str, list[DBCall]], None] | None = None,
+    db_schema: DBSchema | None = None
+) -> tuple[list[dict[str, Any]], dict[str, list[dict[str, Any]]]]:
+    """Discover application database calls from indexed files.
+
+    discovery_options are passed to discover_db_calls as keyword arguments.
+    on_file, if given, is called with (path, calls) after each file.
+    db_schema, if given, supplies the introspected tables' columns and
+    types to the column checks alongside those declared by migrations.
+
+    Returns:
+        The calls as report dicts, and their table index (see build_table_index)
+    """
     files = await conn.fetch(
         """
         SELECT id, path, language
         FROM file
         WHERE repo_id = $1
-          AND language IN ('javascript', 'typescript', 'python', 'go', 'java')
+          AND language IN ('javascript', 'typescript', 'python', 'go', 'java', 'sql')
         ORDER BY mtime DESC
         """,
         repo_id
     )
 
-    all_calls = []
+    discovered = []
 
     for file_row in files:
         file_path = file_row['path']
@@ -429,27 +558,68 @@ async def _discover_app_calls(
         content = '\n'.join(chunk['content'] for chunk in chunks)
 
         # Discover calls
-        calls = discover_db_calls(file_path, content, language)
-
-        for call in calls[:max_calls]:
-            all_calls.append({
-                'file_path': call.file_path,
-                'language': call.language,
-                'framework': call.framework,
-                'call_type': call.call_type,
-                'sql_snippet': call.sql_snippet[:200] if call.sql_snippet else None,
-                'line': call.line,
-                'tags': call.tags
-            })
+        calls = discover_db_calls(file_path, content, language, **(discovery_options or {}))
+        discovered.extend(calls[:max_calls])
+        if on_file:
+            on_file(file_path, calls)
 
-        if len(all_calls) >= max_calls:
+        if len(discovered) >= max_calls:
             break
 
-    return all_calls[:max_calls]
+    # Lock ordering and column checks against migrations compare files
+    find_lock_order_conflicts(discovered)
+    find_dropped_column_references(discovered)
+    schema_columns = _schema_column_types(db_schema)
+    find_unknown_column_writes(
+        discovered, {table: list(columns) for table, columns in schema_columns.items()}
+    )
+    find_returning_unset_columns(discovered)
+    find_type_mismatch_predicates(discovered, schema_columns)
+
+    discovered = discovered[:max_calls]
+    all_calls = []
+    for call in discovered:
+        all_calls.append({
+            'file_path': call.file_path,
+            'language': call.language,
+            'framework': call.framework,
+            'call_type': call.call_type,
+            'sql_snippet': call.sql_snippet[:200] if call.sql_snippet else None,
+            'line': call.start_line,
+            'tags': call.tags,
+            'tables': call.tables,
+            'function': call.function,
+            'routines': [r['name'] for r in call.routines],
+            'risks': call.risks,
+            'query_name': call.query_name,
+            'kind': call.kind
+        })
+
+    return all_calls, build_table_index(discovered)
+
 
+def _schema_column_types(db_schema: DBSchema | None) -> dict[str, dict[str, str]]:
+    """Map each introspected table (schema.name) to its column -> data type."""
+    return {
+        f"{table['schema']}.{table['name']}": {
+            column['column_name']: column['data_type'] for column in table.get('columns', [])
+        }
+        for table in (db_schema.tables if db_schema else [])
+    }
+
+
+def _build_app_calls_summary(
+    calls: list[dict[str, Any]],
+    table_index: dict[str, list[dict[str, Any]]]
+) -> dict[str, Any]:
+    """Build application database calls summary.
+
+    Findings (risk-only call sites without SQL) are counted separately;
+    their risks are reported by the risk analysis.
+    """
+    findings = sum(1 for call in calls if call.get('kind') == 'finding')
+    calls = [call for call in calls if call.get('kind') != 'finding']
 
-def _build_app_calls_summary(calls: list[dict[str, Any]]) -> dict[str, Any]:
-    """Build application database calls summary."""
     # Group by language
     by_language = {}
     for call in calls:
@@ -479,10 +649,12 @@ def _build_app_calls_summary(calls: list[dict[str, Any]]) -> dict[str, Any]:
 
     return {
         'total': len(calls),
+        'findings': findings,
         'by_language': by_language,
         'by_framework': by_framework,
         'migration_calls': len(migration_calls),
         'ddl_calls': len(ddl_calls),
+        'table_index': table_index,
         'sample_calls': calls[:20]
     }
 
@@ -604,6 +776,8 @@ def _generate_markdown(report: dict[str, Any]) -> str:
             lines.append(f"\n**{severity_badge}** - {risk['type']}")
             if 'routine' in risk:
                 lines.append(f"- **Routine:** {risk['routine']}")
+            if 'location' in risk:
+                lines.append(f"- **Location:** {risk['location']}")
             lines.append(f"- **Details:** {risk['details']}")
         lines.append("")
 
@@ -611,6 +785,8 @@ def _generate_markdown(report: dict[str, Any]) -> str:
     lines.append("## Application Database Calls\n")
     app_calls = report['app_db_calls']
     lines.append(f"**Total Calls Discovered:** {app_calls['total']}")
+    if app_calls.get('findings'):
+        lines.append(f"**Findings Without SQL:** {app_calls['findings']}")
     lines.append(f"**Migration Calls:** {app_calls['migration_calls']}")
     lines.append(f"**DDL Calls:** {app_calls['ddl_calls']}\n")
 
@@ -627,6 +803,17 @@ def _generate_markdown(report: dict[str, Any]) -> str:
             lines.append(f"- {fw}: {count}")
         lines.append("")
 
+    if app_calls['table_index']:
+        lines.append("### Table Usage\n")
+        for table, locations in sorted(app_calls['table_index'].items()):
+            lines.append(f"\n**{table}**")
+            for loc in locations:
+                where = f"{loc['file']}:{loc['line']}"
+                if loc['function']:
+                    where += f" ({loc['function']})"
+                lines.append(f"- {where}")
+        lines.append("")
+
     # Migration Info
     lines.append("## Migration Information\n")
     migration = report['migration_info']
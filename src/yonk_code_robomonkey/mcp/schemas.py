@@ -560,6 +560,53 @@ RETURNS: {feature_name: str, related_files: [ranked by relevance], key_symbols:
                     "type": "integer",
                     "description": "Maximum app database calls to discover (default: 100)",
                     "default": 100
+                },
+                "redact": {
+                    "type": "boolean",
+                    "description": "Mask literals and passwords in query text for external sharing (default: false)",
+                    "default": False
+                },
+                "table_patterns": {
+                    "type": "object",
+                    "additionalProperties": {"type": "string"},
+                    "description": "Regex -> canonical table name, to group sharded or prefixed tables in app queries (e.g., {\"tenant_[^.]+\\\\.users\": \"users\"})"
+                },
+                "default_schemas": {
+                    "type": "array",
+                    "items": {"type": "string"},
+                    "description": "Schemas app queries should qualify tables with (e.g., [\"public\"]); unqualified table references are flagged to avoid search_path ambiguity"
+                },
+                "allowed_schemas": {
+                    "type": "array",
+                    "items": {"type": "string"},
+                    "description": "Schemas app queries may touch; qualified table references outside these and allowed_tables are flagged as forbidden"
+                },
+                "allowed_tables": {
+                    "type": "array",
+                    "items": {"type": "string"},
+                    "description": "Tables (bare or schema-qualified) app queries may touch in addition to allowed_schemas"
+                },
+                "audit_table_pattern": {
+                    "type": "string",
+                    "description": "Regex for audit/event/log tables whose plain INSERTs are flagged as non-idempotent (default matches audit, event(s) and log(s) table names)"
+                },
+                "dialect": {
+                    "type": "string",
+                    "description": "SQL dialect for app query checks: postgres, mysql, sqlite or a registered dialect (default: detected per file from its drivers)"
+                },
+                "query_wrappers": {
+                    "type": "object",
+                    "additionalProperties": {"type": "integer"},
+                    "description": "Function name -> zero-based index of its SQL argument, for project helpers that run queries (e.g., {\"tracedQuery\": 2}); their calls are analyzed like native Query calls"
+                },
+                "optional_checks": {
+                    "type": "array",
+                    "items": {"type": "string", "enum": ["nondeterministic-predicate", "nullable-foreign-key", "strict-parameterization", "suspicious-distinct", "untyped-scan", "weak-isolation"]},
+                    "description": "Off-by-default app query checks to run: nondeterministic-predicate (NOW()/CURRENT_TIMESTAMP in a WHERE clause), nullable-foreign-key (foreign key columns without NOT NULL), strict-parameterization (any query text that isn't a constant literal), suspicious-distinct (SELECT DISTINCT over a join), untyped-scan (Go rows read via rows.Values() or into interface{}/any), weak-isolation (transactions reading then updating rows without a lock at the default isolation level)"
+                },
+                "find_table": {
+                    "type": "string",
+                    "description": "Table to look up in the report's table index (e.g., \"orders\" or \"public.orders\"); its file/line/function locations are returned as table_usages"
                 }
             },
             "required": ["repo", "target_db_url"]
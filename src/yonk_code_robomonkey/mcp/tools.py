@@ -27,6 +27,7 @@ from yonk_code_robomonkey.reports.generator import generate_comprehensive_review
 from yonk_code_robomonkey.reports.feature_context import get_feature_context
 from yonk_code_robomonkey.reports.feature_index_builder import build_feature_index as _build_feature_index
 from yonk_code_robomonkey.db_introspect.report_generator import generate_db_architecture_report
+from yonk_code_robomonkey.db_introspect.app_call_discoverer import lookup_table_usages
 from yonk_code_robomonkey.migration.assessor import assess_migration
 from yonk_code_robomonkey.config import Settings, get_schema_name
 from yonk_code_robomonkey.db.schema_manager import (
@@ -1324,7 +1325,17 @@ async def db_review(
     regenerate: bool = False,
     schemas: list[str] | None = None,
     max_routines: int = 50,
-    max_app_calls: int = 100
+    max_app_calls: int = 100,
+    redact: bool = False,
+    table_patterns: dict[str, str] | None = None,
+    default_schemas: list[str] | None = None,
+    allowed_schemas: list[str] | None = None,
+    allowed_tables: list[str] | None = None,
+    audit_table_pattern: str | None = None,
+    dialect: str | None = None,
+    query_wrappers: dict[str, int] | None = None,
+    optional_checks: list[str] | None = None,
+    find_table: str | None = None
 ) -> dict[str, Any]:
     """Generate comprehensive database architecture report.
 
@@ -1338,6 +1349,26 @@ async def db_review(
         schemas: List of schema names to analyze (None = all non-system schemas)
         max_routines: Maximum routines to include in report
         max_app_calls: Maximum app calls to discover
+        redact: Mask literals and passwords in query text for external sharing
+        table_patterns: Regex -> canonical table mapping for sharded or
+            prefixed table names (e.g. {"tenant_[^.]+\\.users": "users"})
+        default_schemas: Schemas tables should be qualified with (e.g. ["public"]);
+            unqualified table references in app queries are flagged
+        allowed_schemas: Schemas app queries may touch; qualified table
+            references outside them and allowed_tables are flagged as forbidden
+        allowed_tables: Tables (bare or schema-qualified) app queries may
+            touch in addition to allowed_schemas
+        audit_table_pattern: Regex for audit/event/log tables whose plain
+            INSERTs are flagged as non-idempotent (default: audit/events/logs names)
+        dialect: SQL dialect for app query checks (postgres, mysql, sqlite);
+            detected per file from its drivers when omitted
+        query_wrappers: Function name -> SQL argument index for project
+            helpers that run queries (e.g. {"tracedQuery": 2} for
+            tracedQuery(ctx, db, "SELECT ...", args))
+        optional_checks: Off-by-default app query checks to run
+            (e.g. ["nondeterministic-predicate"])
+        find_table: Table to look up in the report's table index (bare or
+            schema-qualified); its locations are returned as table_usages
 
     Returns:
         Comprehensive DB report with JSON and markdown
@@ -1353,10 +1384,19 @@ async def db_review(
             regenerate=regenerate,
             schemas=schemas,
             max_routines=max_routines,
-            max_app_calls=max_app_calls
+            max_app_calls=max_app_calls,
+            redact=redact,
+            table_patterns=table_patterns,
+            default_schemas=default_schemas,
+            allowed_schemas=allowed_schemas,
+            allowed_tables=allowed_tables,
+            audit_table_pattern=audit_table_pattern,
+            dialect=dialect,
+            query_wrappers=query_wrappers,
+            optional_checks=optional_checks
         )
 
-        return {
+        response = {
             "cached": result.cached,
             "updated_at": str(result.updated_at),
             "report_markdown": result.report_text,
@@ -1367,6 +1407,11 @@ async def db_review(
                 "sections": list(result.report_json.keys()) if isinstance(result.report_json, dict) else []
             }
         }
+        if find_table:
+            table_index = result.report_json.get("app_db_calls", {}).get("table_index", {})
+            response["table_usages"] = lookup_table_usages(table_index, find_table)
+
+        return response
     except ValueError as e:
         return {
             "error": f"Repository not found: {repo}",
@@ -25,6 +25,9 @@ class ParsedColumn:
     is_foreign_key: bool = False
     fk_references: str | None = None  # "table.column" or "table(column)"
     comment: str | None = None
+    is_unique: bool = False
+    is_auto_increment: bool = False  # SERIAL types, AUTO_INCREMENT, identity columns
+    check: str | None = None  # inline CHECK expression
 
 
 @dataclass
@@ -90,6 +93,10 @@ class ParsedRoutine:
     content_hash: str = ""
 
 
+# Column types that imply an owned sequence and NOT NULL
+SERIAL_TYPES = {"SERIAL", "BIGSERIAL", "SMALLSERIAL", "SERIAL2", "SERIAL4", "SERIAL8"}
+
+
 def detect_sql_dialect(content: str) -> str:
     """Auto-detect SQL dialect from content.
 
@@ -860,18 +867,37 @@ def _parse_column_def(col_expr: exp.ColumnDef) -> ParsedColumn | None:
         is_pk = False
         is_fk = False
         fk_ref = None
+        is_unique = False
+        check = None
+        is_auto_increment = data_type.upper() in SERIAL_TYPES
+
+        # Check constraints (column constraints wrap their kind)
+        for column_constraint in col_expr.constraints:
+            constraint = getattr(column_constraint, 'kind', None) or column_constraint
 
-        # Check constraints
-        for constraint in col_expr.constraints:
             if isinstance(constraint, exp.NotNullColumnConstraint):
-                nullable = False
+                nullable = bool(constraint.args.get('allow_null'))
             elif isinstance(constraint, exp.PrimaryKeyColumnConstraint):
                 is_pk = True
-                nullable = False
             elif isinstance(constraint, exp.DefaultColumnConstraint):
                 default = constraint.this.sql() if constraint.this else None
-            elif hasattr(constraint, 'kind') and 'REFERENCES' in str(constraint.kind).upper():
+            elif isinstance(constraint, exp.UniqueColumnConstraint):
+                is_unique = True
+            elif isinstance(constraint, exp.CheckColumnConstraint):
+                check = constraint.this.sql() if constraint.this else None
+            elif isinstance(constraint, (
+                exp.AutoIncrementColumnConstraint, exp.GeneratedAsIdentityColumnConstraint
+            )):
+                is_auto_increment = True
+            elif isinstance(constraint, exp.Reference):
                 is_fk = True
+                fk_ref = re.sub(r'\s+\(', '(', constraint.this.sql()) if constraint.this else None
+            elif 'REFERENCES' in str(constraint).upper():
+                is_fk = True
+
+        # Primary keys and serial columns are implicitly NOT NULL
+        if is_pk or is_auto_increment:
+            nullable = False
 
         return ParsedColumn(
             name=name,
@@ -880,7 +906,10 @@ def _parse_column_def(col_expr: exp.ColumnDef) -> ParsedColumn | None:
             default=default,
             is_primary_key=is_pk,
             is_foreign_key=is_fk,
-            fk_references=fk_ref
+            fk_references=fk_ref,
+            is_unique=is_unique,
+            is_auto_increment=is_auto_increment,
+            check=check
         )
 
     except Exception:
@@ -967,14 +996,42 @@ def _parse_create_table_fallback(
                 if len(parts) >= 2:
                     col_name = parts[0].strip('"')
                     col_type = parts[1]
-                    is_pk = 'PRIMARY KEY' in col_def.upper()
-                    nullable = 'NOT NULL' not in col_def.upper() and not is_pk
+                    col_upper = col_def.upper()
+                    is_pk = 'PRIMARY KEY' in col_upper
+                    is_auto_increment = (
+                        col_type.upper() in SERIAL_TYPES
+                        or 'AUTO_INCREMENT' in col_upper
+                        or 'AS IDENTITY' in col_upper
+                    )
+                    nullable = 'NOT NULL' not in col_upper and not is_pk and not is_auto_increment
+
+                    default_match = re.search(
+                        r'\bDEFAULT\s+(.+?)(?=\s+(?:NOT\s+NULL|NULL|PRIMARY|UNIQUE|CHECK|REFERENCES|CONSTRAINT)\b|$)',
+                        col_def,
+                        re.IGNORECASE | re.DOTALL
+                    )
+                    fk_match = re.search(r'\bREFERENCES\s+([\w."]+)\s*(\([^)]*\))?', col_def, re.IGNORECASE)
+                    check_match = re.search(r'\bCHECK\s*\(', col_def, re.IGNORECASE)
+                    check = None
+                    if check_match:
+                        check_end = _find_balanced_paren(col_def, check_match.end() - 1)
+                        if check_end != -1:
+                            check = col_def[check_match.end():check_end].strip()
 
                     columns.append(ParsedColumn(
                         name=col_name,
                         data_type=col_type,
                         nullable=nullable,
-                        is_primary_key=is_pk
+                        default=default_match.group(1).strip() if default_match else None,
+                        is_primary_key=is_pk,
+                        is_foreign_key=fk_match is not None,
+                        fk_references=(
+                            fk_match.group(1) + re.sub(r'\s+', '', fk_match.group(2) or '')
+                            if fk_match else None
+                        ),
+                        is_unique=re.search(r'\bUNIQUE\b', col_upper) is not None,
+                        is_auto_increment=is_auto_increment,
+                        check=check
                     ))
 
         content_hash = hashlib.sha256(statement.encode()).hexdigest()[:16]
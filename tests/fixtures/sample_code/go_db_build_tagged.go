@@ -0,0 +1,12 @@
+//go:build postgres && integration
+
+// Query only compiled in with the postgres,integration build tags
+package main
+
+import "database/sql"
+
+func countUsers(db *sql.DB) (int, error) {
+    var count int
+    err := db.QueryRow("SELECT count(*) FROM test_schema.users").Scan(&count)
+    return count, err
+}
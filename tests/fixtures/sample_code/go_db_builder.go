@@ -0,0 +1,53 @@
+// Queries assembled in a strings.Builder with fmt.Fprintf
+package main
+
+import (
+    "database/sql"
+    "fmt"
+    "strings"
+)
+
+// The table name comes from the caller and lands in the query text
+func listFromTable(db *sql.DB, table string, limit int) (*sql.Rows, error) {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "SELECT id, name FROM %s", table)
+    sb.WriteString(" ORDER BY id LIMIT $1")
+    return db.Query(sb.String(), limit)
+}
+
+// Constant format with no verbs
+func listActive(db *sql.DB) (*sql.Rows, error) {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "SELECT id, name FROM test_schema.users WHERE active = true")
+    return db.Query(sb.String())
+}
+
+// The table is interpolated, and so is the status value
+func listByStatus(db *sql.DB, table string, status string) (*sql.Rows, error) {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "SELECT id, name FROM %s WHERE status = '%s'", table, status)
+    return db.Query(sb.String())
+}
+
+// All writes are literals, so the query is folded
+func listRecentOrders(db *sql.DB, customerID int) (*sql.Rows, error) {
+    var sb strings.Builder
+    sb.WriteString("SELECT id, total FROM test_schema.orders")
+    sb.WriteString(" WHERE customer_id = $1")
+    sb.WriteString(" ORDER BY created_at DESC LIMIT 20")
+    return db.Query(sb.String(), customerID)
+}
+
+// The sort column is written from the caller's input
+func listSorted(db *sql.DB, column string) (*sql.Rows, error) {
+    var sb strings.Builder
+    sb.WriteString("SELECT id, name FROM test_schema.users ORDER BY ")
+    sb.WriteString(column)
+    return db.Query(sb.String())
+}
+
+func countUsers(db *sql.DB) (int, error) {
+    var n int
+    err := db.QueryRow(string([]byte("SELECT count(*) FROM test_schema.users"))).Scan(&n)
+    return n, err
+}
@@ -0,0 +1,32 @@
+// cgo wrapper around a C hashing library that also stores results
+package main
+
+/*
+#include <stdlib.h>
+#include <string.h>
+
+// Hashes match what db.Exec("UPDATE test_schema.hashes SET ...") used to store
+
+static unsigned long hash_value(const char *s) {
+    unsigned long h = 5381;
+    while (*s) h = h * 33 + *s++;
+    return h;
+}
+*/
+import "C"
+
+import (
+    "database/sql"
+    "unsafe"
+
+    _ "github.com/lib/pq"
+)
+
+func storeHash(db *sql.DB, userID int, value string) error {
+    cValue := C.CString(value)
+    defer C.free(unsafe.Pointer(cValue))
+
+    hash := uint64(C.hash_value(cValue))
+    _, err := db.Exec("UPDATE test_schema.users SET value_hash = $1 WHERE id = $2", hash, userID)
+    return err
+}
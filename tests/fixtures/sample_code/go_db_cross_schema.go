@@ -0,0 +1,15 @@
+// Service code reaching into another service's schema
+package main
+
+import (
+    "database/sql"
+)
+
+func getApiKey(db *sql.DB, userID int) (string, error) {
+    var key string
+    err := db.QueryRow(`SELECT s.api_key
+        FROM test_schema.users u
+        JOIN other_schema.secrets s ON s.user_id = u.id
+        WHERE u.id = $1`, userID).Scan(&key)
+    return key, err
+}
@@ -0,0 +1,15 @@
+// Order queries written before orders.status was dropped
+package main
+
+import (
+    "database/sql"
+)
+
+func listPendingOrders(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id, total FROM test_schema.orders WHERE status = 'pending'")
+}
+
+// users.status is a different column and stays
+func listOrdersOfActiveUsers(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT o.id FROM test_schema.orders o JOIN test_schema.users u ON u.id = o.user_id WHERE u.status = $1", "active")
+}
@@ -0,0 +1,29 @@
+// Table and column names interpolated into query text
+package main
+
+import (
+    "database/sql"
+    "fmt"
+)
+
+const usersTable = "test_schema.users"
+
+// table comes from the caller
+func listRowsFromTable(db *sql.DB, table string, id int) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT id FROM %s WHERE id = $1", table), id)
+}
+
+// column comes from a request's sort parameter
+func sortUsers(db *sql.DB, column string) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT id, email FROM test_schema.users ORDER BY %s", column))
+}
+
+// The table name is a constant
+func countUsers(db *sql.DB) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT count(*) FROM %s", usersTable))
+}
+
+// A value, not an identifier, is interpolated
+func findUserByEmail(db *sql.DB, email string) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT id FROM test_schema.users WHERE email = '%s'", email))
+}
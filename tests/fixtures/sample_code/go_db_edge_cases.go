@@ -0,0 +1,330 @@
+// Go database access patterns that exercise discovery edge cases
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strconv"
+    "strings"
+    "text/template"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/lib/pq"
+)
+
+// Sharded table name built at runtime
+func getTenantUser(ctx context.Context, pool *pgxpool.Pool, tenantID int, userID int) (string, error) {
+    var username string
+    rows, err := pool.Query(ctx,
+        fmt.Sprintf("SELECT username FROM tenant_%d.users WHERE id = $1", tenantID),
+        userID,
+    )
+    if err != nil {
+        return "", err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        if err := rows.Scan(&username); err != nil {
+            return "", err
+        }
+    }
+    return username, rows.Err()
+}
+
+// Row lock that waits forever if another transaction holds it
+func lockOrderBlocking(ctx context.Context, pool *pgxpool.Pool, orderID int) error {
+    tx, err := pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    var status string
+    err = tx.QueryRow(ctx,
+        `SELECT status FROM test_schema.orders WHERE id = $1 FOR UPDATE`,
+        orderID,
+    ).Scan(&status)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+// Queries annotated with structured comments
+func getUserByEmail(db *sql.DB, email string) (int, error) {
+    var id int
+    err := db.QueryRow(`-- name: GetUserByEmail :one
+        /* app:accounts */
+        SELECT id FROM test_schema.users WHERE email = $1`,
+        email,
+    ).Scan(&id)
+    return id, err
+}
+
+// Two statements sent through one Exec
+func archiveUser(db *sql.DB, userID int) error {
+    _, err := db.Exec("UPDATE test_schema.orders SET status = 'archived' WHERE user_id = $1; DELETE FROM test_schema.users WHERE id = $1", userID)
+    return err
+}
+
+// Upsert keyed on the user's email
+func upsertUser(db *sql.DB, email string, username string) error {
+    _, err := db.Exec(`INSERT INTO test_schema.users (email, username)
+        VALUES ($1, $2)
+        ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, updated_at = now()`,
+        email, username,
+    )
+    return err
+}
+
+// Page size formatted straight into the query text
+func listRecentOrders(db *sql.DB, pageSize int) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT id, status FROM test_schema.orders ORDER BY created_at DESC LIMIT %d", pageSize))
+}
+
+// Joins missing their join conditions
+func listUserOrders(db *sql.DB, status string) (*sql.Rows, error) {
+    rows, err := db.Query(`SELECT u.username, o.id
+        FROM test_schema.users u JOIN test_schema.orders o
+        WHERE o.status = $1`, status)
+    if err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    return db.Query(`SELECT u.username, o.id
+        FROM test_schema.users u, test_schema.orders o
+        WHERE o.status = $1`, status)
+}
+
+// Query text rendered from a text/template
+var countTemplate = template.Must(template.New("count").Parse(
+    "SELECT count(*) FROM {{.Schema}}.{{.Table}} WHERE status = $1",
+))
+
+func countByStatus(db *sql.DB, schema string, table string, status string) (int, error) {
+    var sb strings.Builder
+    if err := countTemplate.Execute(&sb, map[string]string{"Schema": schema, "Table": table}); err != nil {
+        return 0, err
+    }
+
+    var count int
+    err := db.QueryRow(sb.String(), status).Scan(&count)
+    return count, err
+}
+
+// Relies on search_path to resolve the users table
+func countActiveUsers(db *sql.DB) (int, error) {
+    var count int
+    err := db.QueryRow(`WITH active AS (
+        SELECT id FROM users WHERE active = true
+    )
+    SELECT count(*) FROM active`).Scan(&count)
+    return count, err
+}
+
+// Placeholder styles copied from Postgres and MySQL code paths
+func updateUserEmail(db *sql.DB, userID int, email string) error {
+    _, err := db.Exec("UPDATE test_schema.users SET email = ?, updated_at = now()::timestamp WHERE id = $1", email, userID)
+    return err
+}
+
+// Multi-table writes: Postgres DELETE ... USING and UPDATE ... FROM
+func purgeBannedUserOrders(db *sql.DB) error {
+    _, err := db.Exec(`DELETE FROM test_schema.orders o
+        USING test_schema.users u
+        WHERE o.user_id = u.id AND u.banned = true`)
+    return err
+}
+
+func syncOrderEmails(db *sql.DB) error {
+    _, err := db.Exec(`UPDATE test_schema.orders o SET contact_email = u.email
+        FROM test_schema.users u
+        WHERE o.user_id = u.id`)
+    return err
+}
+
+// database/sql transaction started without its context
+func transferCredit(ctx context.Context, db *sql.DB, fromID int, toID int, amount float64) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec("UPDATE test_schema.users SET credit = credit - $1 WHERE id = $2", amount, fromID); err != nil {
+        return err
+    }
+    if _, err := tx.Exec("UPDATE test_schema.users SET credit = credit + $1 WHERE id = $2", amount, toID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// Job queue workers claiming the oldest pending job
+func claimNextJob(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+    var jobID int
+    err := pool.QueryRow(ctx, `SELECT id FROM test_schema.jobs
+        WHERE status = 'pending'
+        ORDER BY created_at
+        LIMIT 1
+        FOR UPDATE`).Scan(&jobID)
+    return jobID, err
+}
+
+func claimNextJobSkipLocked(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+    var jobID int
+    err := pool.QueryRow(ctx, `SELECT id FROM test_schema.jobs
+        WHERE status = 'pending'
+        ORDER BY created_at
+        LIMIT 1
+        FOR UPDATE SKIP LOCKED`).Scan(&jobID)
+    return jobID, err
+}
+
+// Business logic kept in stored routines
+func applyCredit(ctx context.Context, pool *pgxpool.Pool, userID int, amount float64) (float64, error) {
+    var balance float64
+    err := pool.QueryRow(ctx, "SELECT billing.apply_credit($1, round($2, 2)), count(*) FROM test_schema.orders WHERE user_id = $1", userID, amount).Scan(&balance)
+    return balance, err
+}
+
+func refreshStats(ctx context.Context, pool *pgxpool.Pool, userID int) error {
+    _, err := pool.Exec(ctx, "CALL refresh_user_stats($1)", userID)
+    return err
+}
+
+// Audit rows written from a retried handler
+func recordLogin(db *sql.DB, userID int, requestID string) error {
+    _, err := db.Exec("INSERT INTO test_schema.audit_log (user_id, action, request_id) VALUES ($1, 'login', $2)", userID, requestID)
+    return err
+}
+
+func recordLoginOnce(db *sql.DB, userID int, requestID string) error {
+    _, err := db.Exec(`INSERT INTO test_schema.audit_log (user_id, action, request_id) VALUES ($1, 'login', $2)
+        ON CONFLICT (request_id) DO NOTHING`, userID, requestID)
+    return err
+}
+
+// Random sampling by sorting the whole table
+func pickRandomUsers(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id, email FROM test_schema.users ORDER BY random() LIMIT 5")
+}
+
+// Aggregates filtered with HAVING
+func bigSpenders(db *sql.DB, minTotal float64) (*sql.Rows, error) {
+    return db.Query(`SELECT o.user_id, sum(o.total_amount) AS spent
+        FROM test_schema.orders o
+        GROUP BY o.user_id
+        HAVING sum(o.total_amount) > $1 AND count(*) >= 3`, minTotal)
+}
+
+func bigPendingSpenders(db *sql.DB, minTotal float64) (*sql.Rows, error) {
+    return db.Query(`SELECT user_id, sum(total_amount) AS spent
+        FROM test_schema.orders
+        GROUP BY user_id
+        HAVING sum(total_amount) > $1 AND status = 'pending'`, minTotal)
+}
+
+// Takes a search term but never binds it
+func searchUsersByName(db *sql.DB, name string) (*sql.Rows, error) {
+    return db.Query(fmt.Sprintf("SELECT id, username FROM test_schema.users WHERE username LIKE '%s%%'", name))
+}
+
+// Two dependent inserts without a transaction
+func createUserWithProfile(db *sql.DB, userID int, email string, bio string) error {
+    if _, err := db.Exec("INSERT INTO test_schema.users (id, email) VALUES ($1, $2)", userID, email); err != nil {
+        return err
+    }
+    _, err := db.Exec("INSERT INTO test_schema.profiles (user_id, bio) VALUES ($1, $2)", userID, bio)
+    return err
+}
+
+func bumpCounters(db *sql.DB, userID int) {
+    // independent-writes: the counters are best effort and may drift
+    db.Exec("UPDATE test_schema.users SET visits = visits + 1 WHERE id = $1", userID)
+    db.Exec("UPDATE test_schema.site_stats SET visits = visits + 1")
+}
+
+// Bind parameters are not allowed in DDL
+func setDefaultVisits(db *sql.DB, visits int) error {
+    _, err := db.Exec("ALTER TABLE test_schema.users ALTER COLUMN visits SET DEFAULT $1", visits)
+    return err
+}
+
+// JSONB key operators, not ? placeholders
+func usersWithPreference(db *sql.DB, key string, anyOf []string) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM test_schema.users WHERE prefs ? $1 AND tags ?| $2 AND flags ?& array['beta'] AND prefs ? 'theme'", key, pq.Array(anyOf))
+}
+
+// Compares against the database clock
+func recentOrders(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM test_schema.orders WHERE created_at > NOW() - interval '1 day' ORDER BY created_at")
+}
+
+// The database clock outside WHERE is fine
+func touchOrder(db *sql.DB, orderID int) error {
+    _, err := db.Exec("UPDATE test_schema.orders SET updated_at = NOW() WHERE id = $1", orderID)
+    return err
+}
+
+// Reads a row without column types
+func rawUserRow(db *sql.DB, userID int) (any, any, error) {
+    var id, email any
+    err := db.QueryRow("SELECT id, email FROM test_schema.users WHERE id = $1", userID).Scan(&id, &email)
+    return id, email, err
+}
+
+// DISTINCT over a keyed join
+func customersWithOrders(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT DISTINCT u.id, u.email FROM test_schema.users u JOIN test_schema.orders o ON o.user_id = u.id")
+}
+
+// DISTINCT hiding a join without a condition
+func orderedEmails(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT DISTINCT u.email FROM test_schema.users u, test_schema.orders o WHERE o.total > 100")
+}
+
+// Offset appended by string concatenation
+func usersPage(db *sql.DB, offset int) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM test_schema.users ORDER BY id LIMIT 50 OFFSET " + strconv.Itoa(offset))
+}
+
+// Reads the credit and writes it back without locking the row
+func addCredit(ctx context.Context, db *sql.DB, userID int, amount int) error {
+    tx, err := db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var credit int
+    if err := tx.QueryRowContext(ctx, "SELECT credit FROM test_schema.users WHERE id = $1", userID).Scan(&credit); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, "UPDATE test_schema.users SET credit = $1 WHERE id = $2", credit+amount, userID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// The same read-modify-write at SERIALIZABLE
+func addCreditSerializable(ctx context.Context, db *sql.DB, userID int, amount int) error {
+    tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var credit int
+    if err := tx.QueryRowContext(ctx, "SELECT credit FROM test_schema.users WHERE id = $1", userID).Scan(&credit); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, "UPDATE test_schema.users SET credit = $1 WHERE id = $2", credit+amount, userID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
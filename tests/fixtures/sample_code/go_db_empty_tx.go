@@ -0,0 +1,37 @@
+// Transactions that run no statements
+package main
+
+import (
+    "context"
+    "database/sql"
+)
+
+// Left behind when the insert moved elsewhere
+func recordLogin(ctx context.Context, db *sql.DB, userID int) error {
+    tx, err := db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    return tx.Commit()
+}
+
+// The statements run in a helper that is given the transaction
+func recordVisit(ctx context.Context, db *sql.DB, userID int) error {
+    tx, err := db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := bumpVisits(ctx, tx, userID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func bumpVisits(ctx context.Context, tx *sql.Tx, userID int) error {
+    _, err := tx.ExecContext(ctx, "UPDATE test_schema.users SET visits = visits + 1 WHERE id = $1", userID)
+    return err
+}
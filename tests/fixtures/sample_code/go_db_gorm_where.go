@@ -0,0 +1,46 @@
+// GORM conditions built from structs and maps
+package main
+
+import (
+    "context"
+
+    "gorm.io/gorm"
+)
+
+type User struct {
+    ID       int    `gorm:"primaryKey"`
+    Username string `gorm:"size:100"`
+}
+
+// An empty name drops the condition and returns the first user
+func findUserByName(db *gorm.DB, name string) (User, error) {
+    var user User
+    err := db.Where(&User{Username: name}).First(&user).Error
+    return user, err
+}
+
+func findUserByNameMap(db *gorm.DB, name string) (User, error) {
+    var user User
+    err := db.Where(map[string]interface{}{"username": name}).First(&user).Error
+    return user, err
+}
+
+func findUserByNameString(db *gorm.DB, name string) (User, error) {
+    var user User
+    err := db.Where("username = ?", name).First(&user).Error
+    return user, err
+}
+
+// GORM's Begin() is not the database/sql one
+func renameUser(ctx context.Context, db *gorm.DB, id int, name string) error {
+    tx := db.Begin()
+    if err := tx.Model(&User{}).Where("id = ?", id).Update("username", name).Error; err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit().Error
+}
+
+func deactivateUser(db *gorm.DB, id int) error {
+    return db.Exec("UPDATE test_schema.users SET active = false WHERE id = ?", id).Error
+}
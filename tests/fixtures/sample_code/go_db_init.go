@@ -0,0 +1,30 @@
+// Settings loaded from the database at import time
+package main
+
+import (
+    "database/sql"
+)
+
+var db *sql.DB
+var settings = map[string]string{}
+
+func init() {
+    db, _ = sql.Open("postgres", "postgres://localhost/mydb")
+    rows, _ := db.Query("SELECT key, value FROM test_schema.settings")
+    defer rows.Close()
+    for rows.Next() {
+        var key, value string
+        rows.Scan(&key, &value)
+        settings[key] = value
+    }
+}
+
+// Package-level statements after init() are not part of it
+var migrations = []string{
+    "CREATE TABLE IF NOT EXISTS test_schema.settings (key TEXT PRIMARY KEY, value TEXT)",
+    "ALTER TABLE test_schema.settings ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ",
+}
+
+func loadSettings(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT key, value FROM test_schema.settings")
+}
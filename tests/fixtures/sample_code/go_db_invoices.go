@@ -0,0 +1,35 @@
+// Invoice writes checked against the create_invoices migration
+package main
+
+import (
+    "database/sql"
+)
+
+// currency is not a column of invoices
+func createInvoice(db *sql.DB, orderID int, amount float64, currency string) error {
+    _, err := db.Exec("INSERT INTO test_schema.invoices (order_id, amount, currency) VALUES ($1, $2, $3)", orderID, amount, currency)
+    return err
+}
+
+func issueInvoice(db *sql.DB, invoiceID int) error {
+    _, err := db.Exec("UPDATE test_schema.invoices SET issued_at = now() WHERE id = $1", invoiceID)
+    return err
+}
+
+// issued_at isn't set and has no default, so RETURNING always reads NULL
+func openInvoice(db *sql.DB, orderID int, amount float64) (int, sql.NullTime, error) {
+    var id int
+    var issuedAt sql.NullTime
+    err := db.QueryRow("INSERT INTO test_schema.invoices (order_id, amount) VALUES ($1, $2) RETURNING id, issued_at", orderID, amount).Scan(&id, &issuedAt)
+    return id, issuedAt, err
+}
+
+// order_id is an INTEGER compared to a string literal
+func invoicesForOrder42(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM test_schema.invoices WHERE order_id = '42'")
+}
+
+// reference is TEXT compared to a number
+func invoiceByReference(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM test_schema.invoices WHERE reference = 1001 AND amount > 0")
+}
@@ -0,0 +1,16 @@
+// Row locks taken through a join in a single FOR UPDATE query
+package main
+
+import (
+    "database/sql"
+)
+
+// Locks orders then users through the join, the reverse of chargeUserForOrder in go_db_lock_order.go
+func lockOrderWithOwner(db *sql.DB, orderID int) (*sql.Rows, error) {
+    return db.Query("SELECT o.id, u.credit FROM test_schema.orders o JOIN test_schema.users u ON u.id = o.user_id WHERE o.id = $1 FOR UPDATE", orderID)
+}
+
+// Only the order row is locked
+func lockOrderOnly(db *sql.DB, orderID int) (*sql.Rows, error) {
+    return db.Query("SELECT o.id, u.email FROM test_schema.orders o JOIN test_schema.users u ON u.id = o.user_id WHERE o.id = $1 FOR UPDATE OF o", orderID)
+}
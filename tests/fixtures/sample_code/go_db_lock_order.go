@@ -0,0 +1,38 @@
+// Two transactions writing users and orders in opposite orders
+package main
+
+import (
+    "database/sql"
+)
+
+func chargeUserForOrder(db *sql.DB, userID int, orderID int, amount float64) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec("UPDATE test_schema.users SET credit = credit - $1 WHERE id = $2", amount, userID); err != nil {
+        return err
+    }
+    if _, err := tx.Exec("UPDATE test_schema.orders SET status = 'paid' WHERE id = $1", orderID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func refundOrder(db *sql.DB, userID int, orderID int, amount float64) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec("UPDATE test_schema.orders SET status = 'refunded' WHERE id = $1", orderID); err != nil {
+        return err
+    }
+    if _, err := tx.Exec("UPDATE test_schema.users SET credit = credit + $1 WHERE id = $2", amount, userID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
@@ -0,0 +1,28 @@
+// Migrations embedded as a slice of SQL statements
+package main
+
+import (
+    "database/sql"
+)
+
+var migrations = []string{
+    `CREATE TABLE IF NOT EXISTS test_schema.sessions (
+        id BIGSERIAL PRIMARY KEY,
+        user_id INTEGER REFERENCES test_schema.users(id),
+        expires_at TIMESTAMPTZ NOT NULL
+    )`,
+    "CREATE INDEX IF NOT EXISTS sessions_user_idx ON test_schema.sessions (user_id)",
+    "ALTER TABLE test_schema.users ADD COLUMN IF NOT EXISTS last_login TIMESTAMPTZ",
+}
+
+// Not SQL: must not be treated as migrations
+var allowedRoles = []string{"admin", "support", "viewer"}
+
+func migrate(db *sql.DB) error {
+    for _, statement := range migrations {
+        if _, err := db.Exec(statement); err != nil {
+            return err
+        }
+    }
+    return nil
+}
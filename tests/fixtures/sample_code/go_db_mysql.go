@@ -0,0 +1,31 @@
+// Go database client using the MySQL driver
+package main
+
+import (
+    "database/sql"
+
+    _ "github.com/go-sql-driver/mysql"
+)
+
+// RETURNING is Postgres syntax; MySQL rejects it
+func createOrderMySQL(db *sql.DB, userID int, totalAmount float64) (int, error) {
+    var orderID int
+    err := db.QueryRow(
+        "INSERT INTO orders (user_id, total_amount, status) VALUES (?, ?, 'pending') RETURNING id",
+        userID, totalAmount,
+    ).Scan(&orderID)
+    return orderID, err
+}
+
+// MySQL upsert
+func upsertUserMySQL(db *sql.DB, email string, username string) error {
+    _, err := db.Exec(
+        "INSERT INTO users (email, username) VALUES (?, ?) ON DUPLICATE KEY UPDATE username = VALUES(username)",
+        email, username,
+    )
+    return err
+}
+
+func pickRandomOrderMySQL(db *sql.DB) (*sql.Rows, error) {
+    return db.Query("SELECT id FROM orders ORDER BY RAND() LIMIT 1")
+}
@@ -0,0 +1,39 @@
+// Repository types wrapping *sql.DB and *pgxpool.Pool
+package main
+
+import (
+    "context"
+    "database/sql"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserStore struct {
+    db *sql.DB
+}
+
+func (s *UserStore) GetEmail(ctx context.Context, userID int) (string, error) {
+    var email string
+    err := s.db.QueryRowContext(ctx, "SELECT email FROM test_schema.users WHERE id = $1", userID).Scan(&email)
+    return email, err
+}
+
+func (s *UserStore) Deactivate(ctx context.Context, userID int) error {
+    _, err := s.db.ExecContext(ctx, "UPDATE test_schema.users SET active = false WHERE id = $1", userID)
+    return err
+}
+
+type OrderRepository struct {
+    pool *pgxpool.Pool
+}
+
+func (r *OrderRepository) Total(ctx context.Context, orderID int) (float64, error) {
+    var total float64
+    err := r.pool.QueryRow(ctx, "SELECT total_amount FROM test_schema.orders WHERE id = $1", orderID).Scan(&total)
+    return total, err
+}
+
+func (r *OrderRepository) Cancel(ctx context.Context, orderID int) error {
+    _, err := r.pool.Exec(ctx, "DELETE FROM test_schema.orders WHERE id = $1", orderID)
+    return err
+}
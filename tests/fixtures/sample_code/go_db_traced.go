@@ -0,0 +1,21 @@
+// Queries run through a project tracing wrapper
+package main
+
+import (
+    "context"
+    "database/sql"
+)
+
+func tracedQuery(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+    ctx, span := tracer.Start(ctx, "db.query")
+    defer span.End()
+    return db.QueryContext(ctx, query, args...)
+}
+
+func listActiveUsers(ctx context.Context, db *sql.DB) (*sql.Rows, error) {
+    return tracedQuery(ctx, db, "SELECT id, email FROM test_schema.users WHERE active = $1", true)
+}
+
+func pickRandomOrders(ctx context.Context, db *sql.DB, n int) (*sql.Rows, error) {
+    return tracedQuery(ctx, db, "SELECT id FROM test_schema.orders ORDER BY random() LIMIT $1", n)
+}
@@ -0,0 +1,37 @@
+// pgx.Batch usage: several statements queued and sent in one round trip
+package main
+
+import (
+    "context"
+    "math/big"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgtype"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+func closeOrder(ctx context.Context, pool *pgxpool.Pool, orderID int, userID int) error {
+    batch := &pgx.Batch{}
+    batch.Queue("UPDATE test_schema.orders SET status = 'closed' WHERE id = $1", orderID)
+    batch.Queue("INSERT INTO test_schema.order_events (order_id, event) VALUES ($1, $2)", orderID)
+    batch.Queue(`SELECT count(*) FROM test_schema.orders WHERE user_id = $1 AND status = 'open'`, userID)
+
+    results := pool.SendBatch(ctx, batch)
+    defer results.Close()
+
+    for i := 0; i < batch.Len(); i++ {
+        if _, err := results.Exec(); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Arguments with commas inside a struct literal and a string
+func repriceOrder(ctx context.Context, pool *pgxpool.Pool, orderID int, cents int64) error {
+    batch := &pgx.Batch{}
+    batch.Queue("UPDATE test_schema.orders SET total_amount = $1 WHERE id = $2", pgtype.Numeric{Int: big.NewInt(cents), Exp: -2, Valid: true}, orderID)
+    batch.Queue("INSERT INTO test_schema.order_events (order_id, event) VALUES ($1, $2)", orderID, "repriced, (manual)")
+
+    return pool.SendBatch(ctx, batch).Close()
+}
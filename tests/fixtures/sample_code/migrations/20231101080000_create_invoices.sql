@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE test_schema.invoices (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES test_schema.orders(id),
+    amount NUMERIC(12, 2) NOT NULL,
+    CONSTRAINT invoices_amount_positive CHECK (amount > 0)
+);
+ALTER TABLE test_schema.invoices ADD COLUMN issued_at TIMESTAMPTZ;
+ALTER TABLE test_schema.invoices ADD COLUMN reference TEXT;
+
+-- +goose Down
+DROP TABLE test_schema.invoices;
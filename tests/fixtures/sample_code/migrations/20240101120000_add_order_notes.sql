@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE test_schema.orders ADD COLUMN notes TEXT;
+CREATE INDEX idx_orders_status ON test_schema.orders (status);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION test_schema.touch_order() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS test_schema.touch_order();
+DROP INDEX IF EXISTS test_schema.idx_orders_status;
+ALTER TABLE test_schema.orders DROP COLUMN notes;
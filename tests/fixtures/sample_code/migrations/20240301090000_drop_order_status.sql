@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE test_schema.orders DROP COLUMN status;
+
+-- +goose Down
+ALTER TABLE test_schema.orders ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
@@ -0,0 +1,7 @@
+-- +goose Up
+DROP TABLE test_schema.legacy_sessions;
+ALTER TABLE test_schema.users ADD COLUMN tier TEXT NOT NULL;
+ALTER TABLE test_schema.users ADD COLUMN plan TEXT NOT NULL DEFAULT 'free';
+CREATE INDEX CONCURRENTLY idx_users_tier ON test_schema.users (tier);
+CREATE TABLE test_schema.sessions (id SERIAL PRIMARY KEY, user_id INTEGER NOT NULL);
+CREATE INDEX idx_sessions_user ON test_schema.sessions (user_id);
@@ -39,6 +39,21 @@ def get_orders_psycopg2(user_id: int):
         conn.close()
 
 
+# psycopg2 binds %s, including in LIMIT/OFFSET
+def get_orders_page_psycopg2(user_id: int, limit: int, offset: int):
+    """Fetch a page of orders using psycopg2."""
+    conn = psycopg2.connect("dbname=mydb user=postgres password=secret")
+    try:
+        cursor = conn.cursor()
+        cursor.execute(
+            "SELECT id, total_amount FROM test_schema.orders WHERE user_id = %s ORDER BY id LIMIT %s OFFSET %s",
+            (user_id, limit, offset)
+        )
+        return cursor.fetchall()
+    finally:
+        conn.close()
+
+
 # SQLAlchemy ORM example
 Base = declarative_base()
 
@@ -164,3 +179,26 @@ def lock_user_for_update(user_id: int):
         raise
     finally:
         conn.close()
+
+
+class OrderRepository:
+    """Order writes through a shared psycopg2 connection."""
+
+    def __init__(self, conn):
+        self.conn = conn
+
+    def create_order(self, user_id: int, total_amount: float):
+        """Insert an order."""
+        cursor = self.conn.cursor()
+        cursor.execute(
+            "INSERT INTO test_schema.orders (user_id, total_amount) VALUES (%s, %s)",
+            (user_id, total_amount)
+        )
+
+    def cancel_order(self, order_id: int):
+        """Mark an order cancelled."""
+        cursor = self.conn.cursor()
+        cursor.execute(
+            "UPDATE test_schema.orders SET status = 'cancelled' WHERE id = %s",
+            (order_id,)
+        )
@@ -0,0 +1,8 @@
+-- name: GetUser :one
+SELECT id, username, email FROM test_schema.users
+WHERE id = $1;
+
+-- name: ListOrdersByUser :many
+SELECT id, total_amount, status FROM test_schema.orders
+WHERE user_id = $1
+ORDER BY created_at DESC;
@@ -12,7 +12,15 @@ from pathlib import Path
 
 from yonk_code_robomonkey.db_introspect.schema_extractor import extract_db_schema
 from yonk_code_robomonkey.db_introspect.routine_analyzer import analyze_routine
-from yonk_code_robomonkey.db_introspect.app_call_discoverer import discover_db_calls
+from yonk_code_robomonkey.db_introspect.app_call_discoverer import (
+    DIALECT_MARKERS, DIALECT_PLACEHOLDER_STYLES, DIALECT_TYPE_NAMES, DIALECT_UNSUPPORTED_FEATURES,
+    RESERVED_WORDS,
+    build_dependency_manifest, discover_db_calls, find_dropped_column_references,
+    find_lock_order_conflicts, find_returning_unset_columns, find_table_usages,
+    find_type_mismatch_predicates, find_unknown_column_writes, group_batches,
+    lookup_table_usages, redact_risk, redact_sql, register_dialect,
+    scan_repository_for_db_calls, summarize_db_calls
+)
 
 
 # Test database URL - can be overridden with environment variable
@@ -289,3 +297,1090 @@ def test_app_call_basic_functionality():
         assert hasattr(call, 'tags')
         assert isinstance(call.tags, list)
         print(f"Sample call: {call.framework} with tags: {call.tags}")
+
+
+def _discover_fixture(name: str, language: str = "go", **kwargs):
+    """Run app call discovery over a sample_code fixture."""
+    fixture_path = Path(__file__).parent / "fixtures" / "sample_code" / name
+    content = fixture_path.read_text()
+    return discover_db_calls(str(fixture_path), content, language, **kwargs)
+
+
+def test_app_call_tables_go():
+    """Test table extraction from discovered Go queries."""
+    calls = _discover_fixture("go_db_client.go")
+
+    all_tables = set()
+    for call in calls:
+        all_tables.update(call.tables)
+
+    assert "test_schema.orders" in all_tables
+    assert "test_schema.users" in all_tables
+    assert "test_schema.audit_log" in all_tables
+
+
+def test_app_call_table_patterns():
+    """Test sharded table names are normalized via table patterns."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    sharded = next(c for c in calls if "tenant_%d" in c.sql_snippet)
+    assert "users" not in sharded.tables
+    assert "dynamic-table" in sharded.tags
+
+    calls = _discover_fixture(
+        "go_db_edge_cases.go",
+        table_patterns={r"tenant_[^.]+\.users": "users"}
+    )
+    sharded = next(c for c in calls if "tenant_%d" in c.sql_snippet)
+    assert sharded.tables == ["users"]
+    assert "dynamic-table" not in sharded.tags
+
+
+def test_app_call_blocking_lock():
+    """Test FOR UPDATE without NOWAIT/SKIP LOCKED or a timeout is flagged."""
+    calls = _discover_fixture("go_db_client.go")
+    nowait = next(c for c in calls if "NOWAIT" in c.sql_snippet)
+    assert not any("NOWAIT" in risk for risk in nowait.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    blocking = next(c for c in calls if "FOR UPDATE" in c.sql_snippet)
+    assert any("block indefinitely" in risk for risk in blocking.risks)
+
+
+def test_app_call_annotations():
+    """Test leading query comments are surfaced as annotations."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    annotated = next(c for c in calls if c.annotations)
+
+    assert annotated.annotations == ["name: GetUserByEmail :one", "app:accounts"]
+    assert annotated.query_name == "GetUserByEmail"
+    assert annotated.tables == ["test_schema.users"]
+    assert "select" in annotated.tags
+
+
+def test_app_call_build_tagged_file():
+    """Test build-tagged Go files are scanned like any other file."""
+    calls = _discover_fixture("go_db_build_tagged.go")
+    assert any("test_schema.users" in c.tables for c in calls)
+
+
+def test_app_call_multi_statement():
+    """Test a single call carrying several statements is flagged."""
+    calls = _discover_fixture("go_db_client.go")
+    ddl = next(c for c in calls if "CREATE TABLE" in c.sql_snippet)
+    assert not any("statements in a single call" in risk for risk in ddl.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    multi = next(c for c in calls if "DELETE" in c.sql_snippet)
+    assert any(risk.startswith("2 statements in a single call") for risk in multi.risks)
+
+
+def test_app_call_sqlc_generated():
+    """Test queries are extracted from sqlc-generated Go code."""
+    calls = _discover_fixture("go_sqlc_query.sql.go")
+    sqlc_calls = [c for c in calls if c.framework == "sqlc"]
+
+    assert [c.query_name for c in sqlc_calls] == ["GetUser", "ListOrdersByUser"]
+    assert all("generated" in c.tags for c in sqlc_calls)
+    assert all("source: query.sql" in c.annotations for c in sqlc_calls)
+    assert sqlc_calls[1].tables == ["test_schema.orders"]
+
+
+def test_app_call_sqlc_findings_on_source():
+    """Test sqlc findings are reported on the source .sql file, not the generated copy."""
+    # test_schema is outside the allowlist, so every query has a risk
+    generated = _discover_fixture("go_sqlc_query.sql.go", allowed_schemas=["public"])
+    assert [c.query_name for c in generated] == ["GetUser", "ListOrdersByUser"]
+    assert all(c.risks == [] for c in generated)
+
+    source = _discover_fixture("query.sql", language="sql", allowed_schemas=["public"])
+    assert [(c.query_name, c.start_line) for c in source] == [("GetUser", 1), ("ListOrdersByUser", 5)]
+    assert all(c.framework == "sqlc" and c.file_path.endswith("query.sql") for c in source)
+    assert all(any("forbidden" in risk for risk in c.risks) for c in source)
+
+
+def test_app_call_summary_stats():
+    """Test aggregate DB call metrics for the Go fixture."""
+    summary = summarize_db_calls(_discover_fixture("go_db_client.go"))
+
+    assert summary["total_calls"] == 6
+    assert summary["distinct_tables"] == [
+        "test_schema.audit_log", "test_schema.orders", "test_schema.users"
+    ]
+    assert set(summary["by_framework"]) == {"database/sql", "gorm", "pgx"}
+    assert summary["by_operation"] == {"select": 4, "insert": 1, "ddl": 1}
+    assert summary["transaction_calls"] == 2
+    assert summary["locking_calls"] == 1
+    assert summary["ddl_calls"] == 1
+
+
+def test_app_call_dialect_mismatch():
+    """Test dialect-specific syntax is flagged against the wrong dialect."""
+    calls = _discover_fixture("go_db_client.go")
+    assert all(c.dialect == "postgres" for c in calls)
+    assert not any("dialect mismatch" in risk for c in calls for risk in c.risks)
+
+    calls = _discover_fixture("go_db_mysql.go")
+    insert = next(c for c in calls if "RETURNING" in c.sql_snippet)
+    assert insert.dialect == "mysql"
+    assert any("RETURNING" in risk and "dialect mismatch" in risk for risk in insert.risks)
+
+
+def test_app_call_upsert():
+    """Test ON CONFLICT and ON DUPLICATE KEY UPDATE clauses are parsed."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    upsert = next(c for c in calls if "ON CONFLICT" in c.sql_snippet)
+    assert "upsert" in upsert.tags
+    assert upsert.upsert["conflict_target"] == ["email"]
+    assert upsert.upsert["action"] == "update"
+    assert upsert.upsert["update_columns"] == ["username", "updated_at"]
+
+    calls = _discover_fixture("go_db_mysql.go")
+    upsert = next(c for c in calls if "DUPLICATE KEY" in c.sql_snippet)
+    assert upsert.upsert["update_columns"] == ["username"]
+
+    calls = _discover_fixture("go_db_client.go")
+    assert all(c.upsert is None for c in calls)
+
+
+def test_app_call_reserved_word_identifier():
+    """Test unquoted reserved words used as identifiers are flagged."""
+    calls = _discover_fixture("go_db_client.go")
+
+    # Postgres accepts timestamp as a column name
+    ddl = next(c for c in calls if "CREATE TABLE" in c.sql_snippet)
+    assert not any("Reserved word" in risk for risk in ddl.risks)
+
+    select = next(c for c in calls if "ILIKE" in c.sql_snippet)
+    assert not any("Reserved word" in risk for risk in select.risks)
+
+    content = """
+    import _ "github.com/lib/pq"
+
+    func addReview(db *sql.DB, userID int, rating int) error {
+        _, err := db.Exec("INSERT INTO test_schema.reviews (user, rating) VALUES ($1, $2)", userID, rating)
+        return err
+    }
+    """
+    risks = discover_db_calls("reviews.go", content, "go")[0].risks
+    assert "Reserved word 'user' used as an unquoted identifier - quote or rename it for postgres portability" in risks
+
+
+def test_app_call_goose_migration():
+    """Test goose migrations are split into up/down statements."""
+    calls = _discover_fixture("migrations/20240101120000_add_order_notes.sql", language="sql")
+
+    up = [c for c in calls if "migration-up" in c.tags]
+    down = [c for c in calls if "migration-down" in c.tags]
+    assert len(up) == 3
+    assert len(down) == 3
+    assert all(c.framework == "goose" and c.call_type == "migration" for c in calls)
+    assert all("ddl" in c.tags for c in calls)
+
+    # StatementBegin/End keeps the function body as one statement
+    function = next(c for c in up if "FUNCTION" in c.sql_snippet)
+    assert "RETURN NEW;" in function.sql_snippet
+    assert function.start_line == 6
+
+
+def test_app_call_migration_safety():
+    """Test up migration sections are checked for destructive and locking DDL."""
+    calls = _discover_fixture("migrations/20240101120000_add_order_notes.sql", language="sql")
+    index = next(c for c in calls if "CREATE INDEX" in c.sql_snippet)
+    assert any("without CONCURRENTLY" in risk for risk in index.risks)
+    # Dropping what the up migration added is what the down section is for
+    assert all(c.risks == [] for c in calls if "migration-down" in c.tags)
+
+    calls = _discover_fixture("migrations/20240301090000_drop_order_status.sql", language="sql")
+    assert any("DROP COLUMN status" in risk and "not the data" in risk for risk in calls[0].risks)
+
+    calls = _discover_fixture("migrations/20240401100000_retire_sessions.sql", language="sql")
+    risks = {c.start_line: c.risks for c in calls}
+    assert any("no down migration" in risk for risk in risks[2])
+    assert any("ADD COLUMN tier NOT NULL" in risk for risk in risks[3])
+    assert risks[4] == risks[5] == risks[6] == risks[7] == []
+
+
+def test_app_call_dynamic_limit():
+    """Test LIMIT values interpolated into query text are flagged."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    paged = next(c for c in calls if "LIMIT %d" in c.sql_snippet)
+    assert any("LIMIT/OFFSET" in risk for risk in paged.risks)
+
+    calls = _discover_fixture("go_db_client.go")
+    assert not any("LIMIT/OFFSET" in risk for c in calls for risk in c.risks)
+
+    # psycopg2's %s is a bind parameter, not interpolation
+    calls = _discover_fixture("python_db_client.py", "python")
+    paged = next(c for c in calls if "LIMIT %s" in c.sql_snippet)
+    assert not any("LIMIT/OFFSET" in risk for risk in paged.risks)
+
+
+def test_app_call_cartesian_join():
+    """Test joins without a join condition are flagged."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    joins = [c for c in calls if "u.username, o.id" in c.sql_snippet]
+    assert len(joins) == 2
+
+    missing_on = next(c for c in joins if " JOIN " in c.sql_snippet)
+    assert any("JOIN test_schema.orders without ON/USING" in risk for risk in missing_on.risks)
+
+    comma = next(c for c in joins if " JOIN " not in c.sql_snippet)
+    assert comma.tables == ["test_schema.users", "test_schema.orders"]
+    assert any("not linked to other tables" in risk for risk in comma.risks)
+
+    calls = _discover_fixture("go_db_client.go")
+    assert not any("Cartesian join" in risk for c in calls for risk in c.risks)
+
+
+def test_app_call_templated_sql():
+    """Test text/template queries are tagged and don't break analysis."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    templated = next(c for c in calls if c.framework == "text/template")
+
+    assert "templated-sql" in templated.tags
+    assert "dynamic-table" in templated.tags
+    assert "select" in templated.tags
+    assert templated.tables == []
+
+
+def test_app_call_unqualified_table():
+    """Test tables without a schema prefix are flagged when schemas are required."""
+    clean = _discover_fixture("go_db_client.go", default_schemas=["public"])
+    assert not any("Unqualified table" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go", default_schemas=["public"])
+    flagged = [c for c in calls if any("Unqualified table" in risk for risk in c.risks)]
+
+    assert len(flagged) == 1
+    assert flagged[0].tables == ["users", "active"]
+    assert flagged[0].risks == [
+        "Unqualified table 'users' resolves via search_path - qualify it as public.users"
+    ]
+
+    # Off unless default schemas are given
+    calls = _discover_fixture("go_db_edge_cases.go")
+    assert not any("Unqualified table" in risk for call in calls for risk in call.risks)
+
+
+def test_app_call_pgx_batch():
+    """Test statements queued on a pgx.Batch are discovered and grouped."""
+    calls = _discover_fixture("go_pgx_batch.go")
+    queued = [c for c in calls if c.call_type == "batch" and c.function == "closeOrder"]
+
+    assert len(queued) == 3
+    assert all(c.batch == "closeOrder.batch" for c in queued)
+
+    batches = group_batches(calls)
+    assert [b.function for b in batches] == ["closeOrder", "repriceOrder"]
+    assert len(batches[0].queries) == 3
+    assert batches[0].tables == ["test_schema.orders", "test_schema.order_events"]
+
+    # The INSERT binds two placeholders but passes one argument
+    mismatched = [c for c in queued if any("placeholder(s)" in risk for risk in c.risks)]
+    assert len(mismatched) == 1
+    assert "insert" in mismatched[0].tags
+    assert "Queued statement uses 2 placeholder(s) but is given 1 argument(s)" in mismatched[0].risks
+
+    # Commas inside a struct literal or a string don't split arguments
+    repriced = [c for c in calls if c.call_type == "batch" and c.function == "repriceOrder"]
+    assert len(repriced) == 2
+    assert not any("placeholder(s)" in risk for c in repriced for risk in c.risks)
+
+
+def test_app_call_mixed_placeholders():
+    """Test statements mixing placeholder styles are flagged."""
+    clean = _discover_fixture("go_db_client.go") + _discover_fixture("go_db_mysql.go")
+    assert not any("placeholder styles" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("placeholder styles" in risk for risk in c.risks)]
+
+    assert len(flagged) == 1
+    assert "Mixed placeholder styles ($n, ?) in one statement - postgres binds only $n" in flagged[0].risks
+
+    # Without a known dialect any mix is flagged
+    content = 'func f(db *sql.DB) { db.Query("SELECT id FROM test_schema.users WHERE id = $1 AND name = ?") }'
+    assert discover_db_calls("users.go", content, "go")[0].risks == [
+        "Mixed placeholder styles ($n, ?) in one statement - the driver binds only one of them"
+    ]
+
+    # SQLite binds ? and :name together
+    content = 'import "modernc.org/sqlite"\n' + content.replace("$1", ":id")
+    assert discover_db_calls("users.go", content, "go")[0].risks == []
+
+    # JSONB ? / ?| / ?& operators are not placeholders
+    jsonb = next(c for c in calls if c.function == "usersWithPreference")
+    assert not any("placeholder styles" in risk for risk in jsonb.risks)
+
+
+def test_app_call_nondeterministic_predicate():
+    """Test the opt-in check for the database clock in WHERE predicates."""
+    assert not any("WHERE predicate" in risk for call in _discover_fixture("go_db_edge_cases.go") for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go", optional_checks=["nondeterministic-predicate"])
+    flagged = [c.function for c in calls if any("WHERE predicate" in risk for risk in c.risks)]
+    assert flagged == ["recentOrders"]
+
+    recent = next(c for c in calls if c.function == "recentOrders")
+    assert any(risk.startswith("NOW() in a WHERE predicate") for risk in recent.risks)
+
+    with pytest.raises(ValueError):
+        _discover_fixture("go_db_edge_cases.go", optional_checks=["no-such-check"])
+
+
+def test_app_call_nullable_foreign_key():
+    """Test the opt-in check for foreign key columns that allow NULL."""
+    assert not any("is nullable" in risk for call in _discover_fixture("go_db_client.go") for risk in call.risks)
+
+    calls = _discover_fixture("go_db_client.go", optional_checks=["nullable-foreign-key"])
+    flagged = [c for c in calls if any("is nullable" in risk for risk in c.risks)]
+    assert [c.function for c in flagged] == ["createAuditTable"]
+    assert "Foreign key column test_schema.audit_log.user_id is nullable - add NOT NULL unless the relationship is optional" in flagged[0].risks
+
+    # invoices.order_id is declared NOT NULL
+    migration = _discover_fixture("migrations/20231101080000_create_invoices.sql", language="sql", optional_checks=["nullable-foreign-key"])
+    assert not any("is nullable" in risk for call in migration for risk in call.risks)
+
+
+def test_app_call_untyped_scan():
+    """Test the opt-in check for rows read into interface{}/any values."""
+    assert not any("untyped" in risk for call in _discover_fixture("go_db_client.go") for risk in call.risks)
+
+    calls = _discover_fixture("go_db_client.go", optional_checks=["untyped-scan"])
+    flagged = [c for c in calls if any("untyped" in risk for risk in c.risks)]
+    assert [c.function for c in flagged] == ["getOrdersWithPgx"]
+    assert any(risk.startswith("rows.Values() at line 73 ") for risk in flagged[0].risks)
+
+    # Scan into variables declared any; typed Scan destinations are fine
+    calls = _discover_fixture("go_db_edge_cases.go", optional_checks=["untyped-scan"])
+    flagged = [c for c in calls if any("untyped" in risk for risk in c.risks)]
+    assert [c.function for c in flagged] == ["rawUserRow"]
+    assert any(risk.startswith("Scan into id, email at line ") for risk in flagged[0].risks)
+
+
+def test_app_call_strict_parameterization():
+    """Test the opt-in check rejecting any query text that isn't a constant literal."""
+    clean = _discover_fixture("go_db_client.go", optional_checks=["strict-parameterization"])
+    assert not any(risk.startswith("Strict parameterization") for call in clean for risk in call.risks)
+    assert not any(
+        risk.startswith("Strict parameterization")
+        for call in _discover_fixture("go_db_edge_cases.go") for risk in call.risks
+    )
+
+    calls = _discover_fixture("go_db_edge_cases.go", optional_checks=["strict-parameterization"])
+    flagged = {
+        c.function: risk for c in calls for risk in c.risks
+        if risk.startswith("Strict parameterization")
+    }
+    assert flagged["getTenantUser"] == (
+        "Strict parameterization: query text is formatted at runtime - only constant SQL "
+        "with bind parameters is allowed"
+    )
+    assert flagged["usersPage"].startswith("Strict parameterization: query text is concatenated")
+    assert flagged[None].startswith("Strict parameterization: query text is interpolated from a template")
+    assert "recentOrders" not in flagged
+
+    # Even a constant interpolated table name fails, and all-literal builders pass
+    dynamic = _discover_fixture("go_db_dynamic_identifier.go", optional_checks=["strict-parameterization"])
+    assert any(risk.startswith("Strict parameterization") for risk in next(c for c in dynamic if c.function == "countUsers").risks)
+
+    builder = _discover_fixture("go_db_builder.go", optional_checks=["strict-parameterization"])
+    flagged = [c.function for c in builder if any(risk.startswith("Strict parameterization") for risk in c.risks)]
+    assert flagged == ["listFromTable", "listByStatus", "listSorted"]
+
+
+def test_app_call_weak_isolation():
+    """Test the opt-in check for read-modify-write transactions without a lock."""
+    assert not any("Read-modify-write" in risk for call in _discover_fixture("go_db_edge_cases.go") for risk in call.risks)
+    assert not any(
+        "Read-modify-write" in risk
+        for call in _discover_fixture("go_db_client.go", optional_checks=["weak-isolation"])
+        for risk in call.risks
+    )
+
+    calls = _discover_fixture("go_db_edge_cases.go", optional_checks=["weak-isolation"])
+    flagged = [c for c in calls if any("Read-modify-write" in risk for risk in c.risks)]
+
+    # SERIALIZABLE and transfers that never read first are fine
+    assert [c.function for c in flagged] == ["addCredit"]
+    assert "update" in flagged[0].tags
+    assert any(
+        risk.startswith("Read-modify-write in addCredit: rows read at line ")
+        and "SELECT ... FOR UPDATE or use SERIALIZABLE" in risk
+        for risk in flagged[0].risks
+    )
+
+
+def test_app_call_suspicious_distinct():
+    """Test the opt-in check for SELECT DISTINCT over joins."""
+    assert not any("DISTINCT" in risk for call in _discover_fixture("go_db_edge_cases.go") for risk in call.risks)
+    assert not any(
+        "DISTINCT" in risk
+        for call in _discover_fixture("go_db_client.go", optional_checks=["suspicious-distinct"])
+        for risk in call.risks
+    )
+
+    calls = _discover_fixture("go_db_edge_cases.go", optional_checks=["suspicious-distinct"])
+    by_function = {c.function: c for c in calls}
+
+    keyed = by_function["customersWithOrders"]
+    assert "SELECT DISTINCT over a join of test_schema.users, test_schema.orders - check the join keys; DISTINCT can hide duplicates from a missing or wrong condition" in keyed.risks
+
+    # A join without a condition gets the stronger wording
+    unconstrained = by_function["orderedEmails"]
+    assert any(risk.startswith("SELECT DISTINCT over an unconstrained join (") for risk in unconstrained.risks)
+
+    flagged = [c.function for c in calls if any("SELECT DISTINCT" in risk for risk in c.risks)]
+    assert sorted(flagged) == ["customersWithOrders", "orderedEmails"]
+
+
+def test_app_call_table_index():
+    """Test the table index maps tables to the functions that use them."""
+    calls = _discover_fixture("go_db_client.go")
+
+    index = summarize_db_calls(calls)["table_index"]
+    assert set(index) == {"test_schema.users", "test_schema.orders", "test_schema.audit_log"}
+
+    usages = find_table_usages(calls, "orders")
+    assert [u["function"] for u in usages] == ["getOrdersWithPgx", "createOrderWithPgx"]
+    assert all(u["file"].endswith("go_db_client.go") for u in usages)
+    assert find_table_usages(calls, "test_schema.orders") == usages
+    assert find_table_usages(calls, "other_schema.orders") == []
+
+    # The same lookup over a report's serialized index
+    assert lookup_table_usages(index, "orders") == usages
+
+
+def test_app_call_enclosing_method():
+    """Test calls in indented Python methods are attributed to their method."""
+    calls = _discover_fixture("python_db_client.py", "python")
+    writes = [c for c in calls if c.write_tables]
+
+    assert [c.function for c in writes] == ["create_order", "cancel_order"]
+    usages = find_table_usages(calls, "test_schema.orders")
+    assert {"create_order", "cancel_order"} <= {u["function"] for u in usages}
+
+
+def test_app_call_wrapped_handles():
+    """Test queries through DB handles held in repository structs."""
+    calls = _discover_fixture("go_db_repository.go")
+    by_function = {c.function: c for c in calls}
+
+    assert len(calls) == 4
+    assert by_function["GetEmail"].framework == "database/sql"
+    assert by_function["Deactivate"].call_type == "execute"
+    assert by_function["Total"].framework == "pgx"
+    assert by_function["Total"].tables == ["test_schema.orders"]
+    assert "delete" in by_function["Cancel"].tags
+
+
+def test_app_call_multi_table_write():
+    """Test DELETE ... USING and UPDATE ... FROM auxiliary tables are read, not written."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    multi = [c for c in calls if "multi-table-write" in c.tags]
+
+    assert len(multi) == 2
+    for call in multi:
+        assert call.tables == ["test_schema.orders", "test_schema.users"]
+        assert call.write_tables == ["test_schema.orders"]
+        assert not any("Cartesian join" in risk for risk in call.risks)
+
+    # Plain single-table writes aren't tagged
+    archive = next(c for c in calls if c.function == "archiveUser")
+    assert "multi-table-write" not in archive.tags
+    assert archive.write_tables == ["test_schema.orders", "test_schema.users"]
+
+
+def test_app_call_forbidden_table():
+    """Test references outside the allowed schemas/tables are flagged."""
+    clean = _discover_fixture("go_db_client.go", allowed_schemas=["test_schema"])
+    assert not any("allowed schemas" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_cross_schema.go", allowed_schemas=["test_schema"])
+    assert calls[0].risks == [
+        "Table 'other_schema.secrets' is outside the allowed schemas/tables - "
+        "forbidden by the access policy"
+    ]
+
+    # Individual tables can be allowed on top of the schemas
+    calls = _discover_fixture(
+        "go_db_cross_schema.go",
+        allowed_schemas=["test_schema"],
+        allowed_tables=["other_schema.secrets"]
+    )
+    assert calls[0].risks == []
+
+
+def test_app_call_begin_without_context():
+    """Test database/sql Begin() is flagged when a context is available."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any("Begin()" in risk for call in clean for risk in call.risks)
+
+    # GORM's db.Begin() has no BeginTx to switch to
+    gorm_calls = _discover_fixture("go_db_gorm_where.go")
+    assert not any(c.call_type == "transaction" for c in gorm_calls)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    begins = [c for c in calls if c.call_type == "transaction" and not c.sql_snippet]
+
+    assert len(begins) == 1
+    assert begins[0].function == "transferCredit"
+    assert "transactions" in begins[0].tags
+    assert any("use BeginTx(ctx, opts)" in risk for risk in begins[0].risks)
+
+    # The Begin() site is a finding, not a query, in the summary
+    assert begins[0].kind == "finding"
+    summary = summarize_db_calls(calls)
+    assert summary["findings"] == 1
+    assert summary["total_calls"] == len(calls) - 1
+    assert all(sample["snippet"] for sample in summary["sample_calls"])
+
+
+def test_app_call_lock_queue_hazard():
+    """Test ORDER BY/LIMIT row locks without SKIP LOCKED are flagged."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any("workers queue on the same row" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("workers queue on the same row" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["claimNextJob"]
+    skip_locked = next(c for c in calls if c.function == "claimNextJobSkipLocked")
+    assert skip_locked.risks == []
+
+
+def test_app_call_routine_calls():
+    """Test stored function and procedure calls are surfaced, built-ins are not."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any(call.routines for call in clean)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    by_function = {c.function: c for c in calls}
+
+    credit = by_function["applyCredit"]
+    assert credit.routines == [{"name": "billing.apply_credit", "args": ["$1", "round($2, 2)"]}]
+    assert "routine-call" in credit.tags
+
+    assert by_function["refreshStats"].routines == [{"name": "refresh_user_stats", "args": ["$1"]}]
+    assert summarize_db_calls(calls)["routine_calls"] == ["billing.apply_credit", "refresh_user_stats"]
+
+
+def test_app_call_audit_insert_idempotency():
+    """Test plain INSERTs into audit/event tables are flagged."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("retries create duplicates" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["recordLogin"]
+    guarded = next(c for c in calls if c.function == "recordLoginOnce")
+    assert guarded.risks == []
+
+    # The table pattern is configurable
+    calls = _discover_fixture("go_db_edge_cases.go", audit_table_pattern=r"^test_schema\.history$")
+    assert not any("retries create duplicates" in risk for call in calls for risk in call.risks)
+
+
+def test_app_call_dependency_manifest():
+    """Test the manifest of external database objects for the Go client."""
+    manifest = build_dependency_manifest(_discover_fixture("go_db_client.go"))
+
+    assert manifest["schemas"] == ["test_schema"]
+    assert manifest["tables"] == {
+        "test_schema.audit_log": ["id", "user_id", "action", "timestamp"],
+        "test_schema.orders": ["id", "total_amount", "status", "created_at", "user_id"],
+        "test_schema.users": ["id", "username", "email"],
+    }
+    assert manifest["routines"] == []
+    assert manifest["sequences"] == ["test_schema.audit_log_id_seq"]
+    assert manifest["foreign_keys"] == [
+        {"table": "test_schema.audit_log", "column": "user_id", "references": "test_schema.users(id)"}
+    ]
+
+
+def test_app_call_manifest_fingerprint():
+    """Test the manifest fingerprint ignores formatting but tracks schema changes."""
+    fixture_path = Path(__file__).parent / "fixtures" / "sample_code" / "go_db_client.go"
+    content = fixture_path.read_text()
+    fingerprint = build_dependency_manifest(_discover_fixture("go_db_client.go"))["fingerprint"]
+
+    reformatted = content.replace("SELECT id, username, email", "SELECT  id,   username,  email")
+    assert reformatted != content
+    calls = discover_db_calls(str(fixture_path), reformatted, "go")
+    assert build_dependency_manifest(calls)["fingerprint"] == fingerprint
+    assert build_dependency_manifest(list(reversed(calls)))["fingerprint"] == fingerprint
+
+    added = content.replace("action VARCHAR(100)", "action VARCHAR(100),\n            ip_address INET")
+    assert added != content
+    calls = discover_db_calls(str(fixture_path), added, "go")
+    assert build_dependency_manifest(calls)["fingerprint"] != fingerprint
+
+    # Type and constraint changes count; DDL whitespace doesn't
+    for changed in ("action VARCHAR(200)", "action VARCHAR(100) NOT NULL"):
+        modified = content.replace("action VARCHAR(100)", changed)
+        assert modified != content
+        calls = discover_db_calls(str(fixture_path), modified, "go")
+        assert build_dependency_manifest(calls)["fingerprint"] != fingerprint
+
+    spaced = content.replace("action VARCHAR(100)", "action   VARCHAR(100)")
+    calls = discover_db_calls(str(fixture_path), spaced, "go")
+    assert build_dependency_manifest(calls)["fingerprint"] == fingerprint
+
+
+def test_app_call_scan_on_file_callback():
+    """Test the per-file callback fires once per scanned file."""
+    repo_root = Path(__file__).parent / "fixtures" / "sample_code"
+    file_list = [
+        {"path": "go_db_client.go", "language": "go"},
+        {"path": "go_db_mysql.go", "language": "go"},
+        {"path": "README.md", "language": "markdown"},
+    ]
+
+    seen = []
+    streamed = scan_repository_for_db_calls(
+        repo_root, file_list, on_file=lambda path, calls: seen.append((path, len(calls)))
+    )
+    batch = scan_repository_for_db_calls(repo_root, file_list)
+
+    assert [path for path, _ in seen] == ["go_db_client.go", "go_db_mysql.go"]
+    assert sum(count for _, count in seen) == len(streamed) == len(batch)
+
+
+def test_app_call_redact_sql():
+    """Test redaction masks literals but keeps placeholders and identifiers."""
+    sql = "SELECT id, email FROM test_schema.users WHERE api_token = 'tok_live_123' AND id = $1 LIMIT 10"
+    assert redact_sql(sql) == (
+        "SELECT id, email FROM test_schema.users WHERE api_token = '***' AND id = $1 LIMIT ***"
+    )
+
+    assert redact_sql("user=postgres password=secret dbname=mydb") == "user=postgres password=*** dbname=mydb"
+    assert redact_sql("postgresql://app:hunter2@db:5432/orders") == "postgresql://app:***@db:5432/orders"
+
+    # Risk prose keeps its numbers and quoted identifiers; only quoted SQL is masked
+    writes = "createUser makes 2 writes (lines 7, 11) outside a transaction - a failure part way leaves them inconsistent"
+    assert redact_risk(writes) == writes
+    assert redact_risk("Unqualified table 'users' resolves via search_path") == "Unqualified table 'users' resolves via search_path"
+    assert redact_risk("users.id is INTEGER but is compared to `'42'` - or `1001`") == (
+        "users.id is INTEGER but is compared to `'***'` - or `***`"
+    )
+    assert redact_risk("Connects with password=secret") == "Connects with password=***"
+
+
+def test_app_call_random_order_by():
+    """Test ORDER BY random()/RAND() is flagged."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any("ORDER BY random()" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go") + _discover_fixture("go_db_mysql.go")
+    flagged = [c for c in calls if any("ORDER BY random()" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["pickRandomUsers", "pickRandomOrderMySQL"]
+
+
+def test_app_call_having_validation():
+    """Test HAVING is surfaced and ungrouped, non-aggregated columns are flagged."""
+    calls = _discover_fixture("go_db_edge_cases.go")
+    by_function = {c.function: c for c in calls}
+
+    valid = by_function["bigSpenders"]
+    assert valid.having == "sum(o.total_amount) > $1 AND count(*) >= 3"
+    assert not any("HAVING" in risk for risk in valid.risks)
+
+    invalid = by_function["bigPendingSpenders"]
+    assert [r for r in invalid.risks if "HAVING" in r] == [
+        "HAVING references 'status', which is neither aggregated nor in GROUP BY - "
+        "move the condition to WHERE or aggregate it"
+    ]
+
+
+def test_app_call_custom_dialect():
+    """Test a registered dialect drives detection and dialect-specific checks."""
+    content = """
+    import _ "github.com/cockroachdb/cockroach-go/v2/crdb"
+
+    func addUser(db *sql.DB, id int, region string) error {
+        _, err := db.Exec("INSERT INTO test_schema.users (id, region) VALUES ($1, $2)", id, region)
+        return err
+    }
+
+    func freezeUsers(db *sql.DB) error {
+        _, err := db.Exec("LOCK TABLE test_schema.users IN EXCLUSIVE MODE")
+        return err
+    }
+    """
+
+    register_dialect(
+        "cockroachdb",
+        markers=[r"github\.com/cockroachdb/cockroach-go"],
+        reserved_words={"region"},
+        unsupported_features=[(r"\bLOCK\s+TABLE\b", "LOCK TABLE")],
+        placeholder_styles=["$n", "?"],
+        type_names={"STRING": "text"}
+    )
+    try:
+        calls = discover_db_calls("users.go", content, "go")
+        assert calls[0].dialect == "cockroachdb"
+        assert calls[0].risks == [
+            "Reserved word 'region' used as an unquoted identifier - "
+            "quote or rename it for cockroachdb portability"
+        ]
+        assert calls[1].risks == ["Uses LOCK TABLE, which cockroachdb does not support - dialect mismatch"]
+
+        # An explicit dialect overrides detection
+        calls = discover_db_calls("users.go", content, "go", dialect="postgres")
+        assert calls[0].dialect == "postgres"
+        assert calls[0].risks == calls[1].risks == []
+
+        # The dialect's placeholder styles and type names are used by the checks
+        mixed = 'func f(db *sql.DB) { db.Query("SELECT id FROM test_schema.users WHERE id = $1 AND name = ? AND code = 7") }'
+        calls = discover_db_calls("users.go", mixed, "go", dialect="cockroachdb")
+        assert calls[0].risks == []
+        mismatches = find_type_mismatch_predicates(calls, known_types={"test_schema.users": {"code": "STRING"}})
+        assert [(m["column"], m["literal"]) for m in mismatches] == [("code", "7")]
+
+        with pytest.raises(ValueError):
+            register_dialect("spanner", markers=[], placeholder_styles=["@name"])
+    finally:
+        for table in (
+            DIALECT_MARKERS, RESERVED_WORDS, DIALECT_UNSUPPORTED_FEATURES,
+            DIALECT_PLACEHOLDER_STYLES, DIALECT_TYPE_NAMES
+        ):
+            table.pop("cockroachdb", None)
+
+
+def test_app_call_lock_order_conflicts():
+    """Test transactions locking tables in opposite orders are reported."""
+    assert find_lock_order_conflicts(_discover_fixture("go_db_client.go")) == []
+
+    calls = _discover_fixture("go_db_lock_order.go")
+    conflicts = find_lock_order_conflicts(calls)
+
+    assert len(conflicts) == 1
+    assert conflicts[0]["tables"] == ["test_schema.users", "test_schema.orders"]
+    assert conflicts[0]["first"]["function"] == "chargeUserForOrder"
+    assert conflicts[0]["second"]["function"] == "refundOrder"
+    assert conflicts[0]["second"]["order"] == ["test_schema.orders", "test_schema.users"]
+
+    flagged = [c for c in calls if any("possible deadlock" in risk for risk in c.risks)]
+    assert sorted(c.function for c in flagged) == ["chargeUserForOrder", "refundOrder"]
+
+
+def test_app_call_no_params_with_input():
+    """Test queries without bind parameters in functions taking string input."""
+    clean = _discover_fixture("go_db_client.go") + _discover_fixture("python_db_client.py", "python")
+    assert not any("No bind parameters" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("No bind parameters" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["searchUsersByName"]
+
+
+def test_app_call_migration_slice():
+    """Test SQL migrations in a Go []string are analyzed per element."""
+    calls = _discover_fixture("go_db_migration_slice.go")
+
+    assert len(calls) == 3
+    assert all(c.call_type == "migration" and c.framework == "migration-slice" for c in calls)
+    assert all("ddl" in c.tags and "migrations" in c.tags for c in calls)
+    assert calls[0].tables == ["test_schema.sessions"]
+    assert calls[0].start_line == 9
+    assert calls[2].tables == ["test_schema.users"]
+
+
+def test_app_call_cgo_file():
+    """Test cgo files are analyzed without picking up the C preamble."""
+    calls = _discover_fixture("go_db_cgo.go")
+
+    assert len(calls) == 1
+    assert calls[0].function == "storeHash"
+    assert calls[0].start_line == 30
+    assert calls[0].tables == ["test_schema.users"]
+    assert "cgo" in calls[0].tags
+
+
+def test_app_call_writes_not_transactional():
+    """Test several writes in a function without a transaction are flagged."""
+    clean = _discover_fixture("go_db_client.go") + _discover_fixture("go_db_lock_order.go")
+    assert not any("outside a transaction" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("outside a transaction" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["createUserWithProfile", "createUserWithProfile"]
+    assert any("makes 2 writes" in risk for risk in flagged[0].risks)
+
+    # One write in each of two methods of a class is not a multi-write
+    calls = _discover_fixture("python_db_client.py", "python")
+    assert [c.function for c in calls if c.write_tables] == ["create_order", "cancel_order"]
+    assert not any("outside a transaction" in risk for call in calls for risk in call.risks)
+
+
+def test_app_call_query_wrappers():
+    """Test registered query wrappers are analyzed like native Query calls."""
+    assert _discover_fixture("go_db_traced.go") == []
+
+    calls = _discover_fixture("go_db_traced.go", query_wrappers={"tracedQuery": 2})
+
+    assert [c.function for c in calls] == ["listActiveUsers", "pickRandomOrders"]
+    assert calls[0].framework == "tracedQuery"
+    assert calls[0].tables == ["test_schema.users"]
+    assert "select" in calls[0].tags
+    assert any("ORDER BY random()" in risk for risk in calls[1].risks)
+
+
+def test_app_call_joined_lock_order():
+    """Test FOR UPDATE over a join records the implied lock order."""
+    calls = _discover_fixture("go_db_joined_lock.go")
+    by_function = {c.function: c for c in calls}
+
+    joined = by_function["lockOrderWithOwner"]
+    assert joined.lock_tables == ["test_schema.orders", "test_schema.users"]
+    assert any(risk.startswith("Joined lock order") for risk in joined.risks)
+
+    narrowed = by_function["lockOrderOnly"]
+    assert narrowed.lock_tables == ["test_schema.orders"]
+    assert not any(risk.startswith("Joined lock order") for risk in narrowed.risks)
+
+    conflicts = find_lock_order_conflicts(calls + _discover_fixture("go_db_lock_order.go"))
+    assert {"chargeUserForOrder", "lockOrderWithOwner"} in [
+        {c["first"]["function"], c["second"]["function"]} for c in conflicts
+    ]
+
+
+def test_app_call_dropped_column_references():
+    """Test queries using a column dropped by an up migration are flagged."""
+    migrations = _discover_fixture("migrations/20240301090000_drop_order_status.sql", language="sql")
+    calls = _discover_fixture("go_db_dropped_column.go")
+
+    references = find_dropped_column_references(migrations + calls)
+
+    assert [r["reference"]["function"] for r in references] == ["listPendingOrders"]
+    assert references[0]["table"] == "test_schema.orders"
+    assert references[0]["column"] == "status"
+    assert references[0]["migration"]["line"] == 2
+
+    # The down migration drops notes again, which the up migration added
+    assert find_dropped_column_references(
+        _discover_fixture("migrations/20240101120000_add_order_notes.sql", language="sql")
+    ) == []
+
+
+def test_app_call_unknown_column_writes():
+    """Test writes to columns missing from the known schema are flagged."""
+    migrations = _discover_fixture("migrations/20231101080000_create_invoices.sql", language="sql")
+    calls = _discover_fixture("go_db_invoices.go")
+
+    unknown = find_unknown_column_writes(migrations + calls)
+
+    assert [(u["function"], u["column"]) for u in unknown] == [("createInvoice", "currency")]
+    assert unknown[0]["table"] == "test_schema.invoices"
+
+    # Tables without known columns are not checked
+    client_calls = _discover_fixture("go_db_client.go")
+    assert find_unknown_column_writes(client_calls) == []
+
+    unknown = find_unknown_column_writes(client_calls, known_columns={
+        "test_schema.orders": ["id", "user_id", "total_amount", "created_at"],
+    })
+    assert ("createOrderWithPgx", "status") in [(u["function"], u["column"]) for u in unknown]
+
+
+def test_app_call_returning_unset_columns():
+    """Test RETURNING columns the INSERT doesn't set and that have no default are flagged."""
+    migrations = _discover_fixture("migrations/20231101080000_create_invoices.sql", language="sql")
+    calls = _discover_fixture("go_db_invoices.go")
+
+    unset = find_returning_unset_columns(migrations + calls)
+
+    # id is SERIAL, so only issued_at is unset
+    assert [(u["function"], u["column"]) for u in unset] == [("openInvoice", "issued_at")]
+    assert unset[0]["table"] == "test_schema.invoices"
+    opened = next(c for c in calls if c.function == "openInvoice")
+    assert any(risk.startswith("RETURNING test_schema.invoices.issued_at") for risk in opened.risks)
+
+    # Tables without known columns are not checked
+    assert find_returning_unset_columns(_discover_fixture("go_db_client.go")) == []
+
+
+def test_app_call_type_mismatch_predicates():
+    """Test predicates comparing a column to a literal of the other type kind are flagged."""
+    migrations = _discover_fixture("migrations/20231101080000_create_invoices.sql", language="sql")
+    calls = _discover_fixture("go_db_invoices.go")
+
+    mismatches = find_type_mismatch_predicates(migrations + calls)
+
+    assert [(m["function"], m["column"], m["literal"]) for m in mismatches] == [
+        ("invoicesForOrder42", "order_id", "'42'"),
+        ("invoiceByReference", "reference", "1001"),
+    ]
+    assert mismatches[0]["type"] == "INTEGER"
+    flagged = next(c for c in calls if c.function == "invoicesForOrder42")
+    assert any(risk.startswith("test_schema.invoices.order_id is INTEGER but is compared to `'42'`") for risk in flagged.risks)
+
+    # Bind parameters are never mismatched
+    client_calls = _discover_fixture("go_db_client.go")
+    assert find_type_mismatch_predicates(client_calls, known_types={
+        "test_schema.orders": {"user_id": "INTEGER", "status": "VARCHAR(50)"},
+    }) == []
+
+    # Introspected types use information_schema spellings
+    introspected = find_type_mismatch_predicates(_discover_fixture("go_db_invoices.go"), known_types={
+        "test_schema.invoices": {"order_id": "integer", "reference": "character varying"},
+    })
+    assert [(m["column"], m["type"]) for m in introspected] == [
+        ("order_id", "integer"), ("reference", "character varying"),
+    ]
+
+
+def test_app_call_parameterized_ddl():
+    """Test DDL executed with bind parameters is flagged."""
+    clean = _discover_fixture("go_db_client.go") + _discover_fixture(
+        "migrations/20240101120000_add_order_notes.sql", language="sql"
+    )
+    assert not any("Parameterized DDL" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_edge_cases.go")
+    flagged = [c for c in calls if any("Parameterized DDL" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["setDefaultVisits"]
+
+
+def test_app_call_exec_framework():
+    """Test db.Exec is attributed to database/sql or GORM by the handle in use."""
+    calls = _discover_fixture("go_db_client.go")
+    ddl = next(c for c in calls if c.function == "createAuditTable")
+    assert ddl.framework == "database/sql"
+
+    # A file importing only GORM
+    gorm_calls = _discover_fixture("go_db_gorm_where.go")
+    update = next(c for c in gorm_calls if c.function == "deactivateUser")
+    assert update.framework == "gorm"
+    assert update.call_type == "execute"
+
+
+def test_app_call_gorm_struct_where():
+    """Test GORM struct conditions, which skip zero values, are flagged."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any("zero-value fields" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_gorm_where.go")
+    flagged = [c for c in calls if any("zero-value fields" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["findUserByName"]
+    assert flagged[0].framework == "gorm"
+
+    # The struct condition is a finding and doesn't count as a query
+    assert flagged[0].kind == "finding"
+    summary = summarize_db_calls(calls)
+    assert summary["total_calls"] == len(calls) - len(flagged)
+    assert summary["findings"] == len(flagged)
+
+
+def test_app_call_fprintf_builder():
+    """Test queries formatted into a Builder with fmt.Fprintf are analyzed."""
+    calls = _discover_fixture("go_db_builder.go")
+    by_function = {c.function: c for c in calls}
+
+    dynamic = by_function["listFromTable"]
+    assert dynamic.framework == "fmt.Fprintf"
+    assert "dynamic-table" in dynamic.tags
+
+    # One risk for the interpolated table; LIMIT $1 is written after the Fprintf
+    assert dynamic.risks == [
+        "Identifier injection: table name interpolated from listFromTable's string input - "
+        "identifiers can't be bound; check them against an allowlist"
+    ]
+
+    # Value and identifier interpolations are reported separately
+    mixed = by_function["listByStatus"]
+    assert mixed.risks == [
+        "Query text built with fmt.Fprintf interpolating 1 value(s) - injection risk "
+        "unless they are constants; bind them as parameters",
+        "Identifier injection: table name interpolated from listByStatus's string input - "
+        "identifiers can't be bound; check them against an allowlist"
+    ]
+
+    static = by_function["listActive"]
+    assert static.tables == ["test_schema.users"]
+    assert not any("fmt.Fprintf" in risk for risk in static.risks)
+
+
+def test_app_call_builder_write_string():
+    """Test WriteString chains and []byte conversions are folded into the query."""
+    calls = _discover_fixture("go_db_builder.go")
+    by_function = {c.function: c for c in calls}
+
+    folded = by_function["listRecentOrders"]
+    assert folded.framework == "strings.Builder"
+    assert folded.sql_snippet == (
+        "SELECT id, total FROM test_schema.orders WHERE customer_id = $1 "
+        "ORDER BY created_at DESC LIMIT 20"
+    )
+    assert folded.tables == ["test_schema.orders"]
+    assert folded.risks == []
+    assert "dynamic-query" not in folded.tags
+
+    # A non-literal write is an interpolation
+    dynamic = by_function["listSorted"]
+    assert "dynamic-query" in dynamic.tags
+    assert dynamic.risks == [
+        "Identifier injection: column name interpolated from listSorted's string input - "
+        "identifiers can't be bound; check them against an allowlist"
+    ]
+
+    converted = by_function["countUsers"]
+    assert converted.sql_snippet == "SELECT count(*) FROM test_schema.users"
+
+    # Builders written with Fprintf are only reported at the Fprintf call
+    assert [c.framework for c in calls if c.function == "listFromTable"] == ["fmt.Fprintf"]
+
+
+def test_app_call_empty_transaction():
+    """Test transactions committed without running a statement are flagged."""
+    for fixture in ("go_db_client.go", "go_db_edge_cases.go", "go_db_lock_order.go", "go_db_gorm_where.go"):
+        calls = _discover_fixture(fixture)
+        assert not any(risk.startswith("Empty transaction") for call in calls for risk in call.risks)
+
+    calls = _discover_fixture("go_db_empty_tx.go")
+    flagged = [c for c in calls if any(risk.startswith("Empty transaction") for risk in c.risks)]
+
+    # recordVisit hands its transaction to a helper
+    assert [c.function for c in flagged] == ["recordLogin"]
+    assert flagged[0].risks == [
+        "Empty transaction: tx is committed at line 17 without running any statements - "
+        "remove it or move the missing work into it"
+    ]
+    assert flagged[0].framework == "database/sql"
+    assert flagged[0].kind == "finding"
+    assert summarize_db_calls(calls)["findings"] == 1
+
+
+def test_app_call_query_in_init():
+    """Test queries issued from a package init() are flagged."""
+    clean = _discover_fixture("go_db_client.go")
+    assert not any("init()" in risk for call in clean for risk in call.risks)
+
+    calls = _discover_fixture("go_db_init.go")
+    flagged = [c for c in calls if any("init()" in risk for risk in c.risks)]
+
+    assert [c.function for c in flagged] == ["init"]
+
+    # The migrations slice declared after init() is package-level code
+    slice_calls = [c for c in calls if c.framework == "migration-slice"]
+    assert len(slice_calls) == 2
+    assert all(c.function is None and not c.risks for c in slice_calls)
+
+
+def test_app_call_identifier_injection():
+    """Test identifiers interpolated into queries are told apart from values."""
+    calls = _discover_fixture("go_db_dynamic_identifier.go")
+    by_function = {c.function: c.risks for c in calls}
+
+    def risk(function: str, prefix: str) -> str | None:
+        return next((r for r in by_function[function] if r.startswith(prefix)), None)
+
+    assert "table name" in risk("listRowsFromTable", "Identifier injection")
+    assert "column name" in risk("sortUsers", "Identifier injection")
+    assert risk("countUsers", "Identifier injection") is None
+    assert "table name" in risk("countUsers", "Dynamic identifier")
+    assert risk("findUserByEmail", "Identifier injection") is None
+    assert risk("findUserByEmail", "Dynamic identifier") is None
@@ -137,6 +137,54 @@ class TestCreateTableParsing:
         # Note: Default value extraction may vary depending on sqlglot version
         # The important thing is that columns are parsed correctly
 
+    def test_column_constraints(self):
+        """Should capture PK, serial, FK and DEFAULT constraints per column."""
+        sql = """
+        CREATE TABLE IF NOT EXISTS test_schema.audit_log (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER REFERENCES test_schema.users(id),
+            action VARCHAR(100),
+            timestamp TIMESTAMPTZ DEFAULT now()
+        )
+        """
+        table = parse_create_table(sql)
+
+        assert table is not None
+        columns = {c.name: c for c in table.columns}
+
+        assert columns["id"].is_primary_key
+        assert columns["id"].is_auto_increment
+        assert not columns["id"].nullable
+
+        assert columns["user_id"].is_foreign_key
+        assert columns["user_id"].fk_references == "test_schema.users(id)"
+        assert columns["user_id"].nullable
+
+        assert columns["action"].nullable
+        assert columns["action"].default is None
+        assert not columns["action"].is_unique
+
+        assert columns["timestamp"].default.lower() == "now()"
+        assert not columns["timestamp"].is_auto_increment
+
+    def test_not_null_unique_check_constraints(self):
+        """Should capture NOT NULL, UNIQUE and CHECK column constraints."""
+        sql = """
+        CREATE TABLE accounts (
+            email TEXT NOT NULL UNIQUE,
+            balance NUMERIC CHECK (balance >= 0)
+        );
+        """
+        table = parse_create_table(sql)
+
+        assert table is not None
+        columns = {c.name: c for c in table.columns}
+
+        assert not columns["email"].nullable
+        assert columns["email"].is_unique
+        assert columns["balance"].check == "balance >= 0"
+        assert columns["balance"].nullable
+
     def test_various_data_types(self):
         """Should parse various PostgreSQL data types."""
         sql = """